@@ -8,14 +8,24 @@ import (
 	"net/mail"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"log/slog"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/till/golangoss-bluesky/internal/cache"
 	"github.com/till/golangoss-bluesky/internal/cmd"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/content"
+	"github.com/till/golangoss-bluesky/internal/moderation"
+	"github.com/till/golangoss-bluesky/internal/policy"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/spam"
 	"github.com/till/golangoss-bluesky/internal/stats"
+	"github.com/till/golangoss-bluesky/internal/submission"
 	"github.com/till/golangoss-bluesky/internal/utils"
 	"github.com/urfave/cli/v3"
 )
@@ -43,9 +53,9 @@ func main() {
 		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     "bluesky-app-key",
-				Sources:  cli.EnvVars("BLUESKY_APP_KEY"),
-				Required: true,
+				Name:    "bluesky-app-key",
+				Usage:   "required to post, the optout command runs without it",
+				Sources: cli.EnvVars("BLUESKY_APP_KEY"),
 			},
 			&cli.StringFlag{
 				Name:     "aws-endpoint",
@@ -63,9 +73,199 @@ func main() {
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "github-token",
-				Sources:  cli.EnvVars("GH_TOKEN"),
-				Required: true,
+				Name:    "github-token",
+				Usage:   "personal access token, required unless the GitHub App flags are set",
+				Sources: cli.EnvVars("GH_TOKEN"),
+			},
+			&cli.Int64Flag{
+				Name:    "github-app-id",
+				Sources: cli.EnvVars("GITHUB_APP_ID"),
+			},
+			&cli.Int64Flag{
+				Name:    "github-app-installation-id",
+				Sources: cli.EnvVars("GITHUB_APP_INSTALLATION_ID"),
+			},
+			&cli.StringFlag{
+				Name:    "github-app-private-key",
+				Usage:   "PEM encoded private key of the GitHub App",
+				Sources: cli.EnvVars("GITHUB_APP_PRIVATE_KEY"),
+			},
+			&cli.BoolFlag{
+				Name:    "require-license",
+				Usage:   "skip repos without a recognized license",
+				Sources: cli.EnvVars("REQUIRE_LICENSE"),
+				Value:   true,
+			},
+			&cli.StringSliceFlag{
+				Name:    "allowed-licenses",
+				Usage:   "SPDX IDs a repo may be licensed under, empty allows any",
+				Sources: cli.EnvVars("ALLOWED_LICENSES"),
+				Value:   config.OSILicenses,
+			},
+			&cli.BoolFlag{
+				Name:    "show-license",
+				Usage:   "add the SPDX ID to the post",
+				Sources: cli.EnvVars("SHOW_LICENSE"),
+			},
+			&cli.IntFlag{
+				Name:    "min-stars",
+				Sources: cli.EnvVars("MIN_STARS"),
+			},
+			&cli.IntFlag{
+				Name:    "max-stars",
+				Usage:   "skip repos above this many stars, 0 disables the cap",
+				Sources: cli.EnvVars("MAX_STARS"),
+			},
+			&cli.IntFlag{
+				Name:    "min-size-kb",
+				Usage:   "skip repos smaller than this, 1 skips empty repos",
+				Sources: cli.EnvVars("MIN_SIZE_KB"),
+				Value:   1,
+			},
+			&cli.BoolFlag{
+				Name:    "exclude-forks",
+				Sources: cli.EnvVars("EXCLUDE_FORKS"),
+				Value:   true,
+			},
+			&cli.BoolFlag{
+				Name:    "exclude-templates",
+				Sources: cli.EnvVars("EXCLUDE_TEMPLATES"),
+				Value:   true,
+			},
+			&cli.BoolFlag{
+				Name:    "exclude-mirrors",
+				Sources: cli.EnvVars("EXCLUDE_MIRRORS"),
+				Value:   true,
+			},
+			&cli.BoolFlag{
+				Name:    "require-description",
+				Usage:   "skip repos that have neither a description nor a README paragraph to use instead",
+				Sources: cli.EnvVars("REQUIRE_DESCRIPTION"),
+				Value:   true,
+			},
+			&cli.BoolFlag{
+				Name:    "require-go-mod",
+				Usage:   "skip GitHub repos without a go.mod",
+				Sources: cli.EnvVars("REQUIRE_GO_MOD"),
+			},
+			&cli.StringSliceFlag{
+				Name:    "score-weights",
+				Usage:   "signal=weight pairs overriding the default ranking weights, 0 disables a signal (signals: stars, forks, description, license, topics, recent_push, issues, star_growth)",
+				Sources: cli.EnvVars("SCORE_WEIGHTS"),
+			},
+			&cli.BoolFlag{
+				Name:    "pick-top",
+				Usage:   "post the best ranked candidate instead of sampling by score",
+				Sources: cli.EnvVars("PICK_TOP"),
+			},
+			&cli.DurationFlag{
+				Name:    "trend-window",
+				Usage:   "period star growth is measured over, in whole days; 0 disables trend tracking",
+				Sources: cli.EnvVars("TREND_WINDOW"),
+				Value:   7 * 24 * time.Hour,
+			},
+			&cli.StringFlag{
+				Name:    "policy-file",
+				Usage:   "JSON file with owners, repos and keywords to deny, allow or boost",
+				Sources: cli.EnvVars("POLICY_FILE"),
+			},
+			&cli.StringSliceFlag{
+				Name:    "hashtag-map",
+				Usage:   "topic=tag pairs renaming repo topics, an empty tag drops the topic",
+				Sources: cli.EnvVars("HASHTAG_MAP"),
+			},
+			&cli.IntFlag{
+				Name:    "max-hashtags",
+				Usage:   "max hashtags per post, including the language tag",
+				Sources: cli.EnvVars("MAX_HASHTAGS"),
+				Value:   4,
+			},
+			&cli.IntFlag{
+				Name:    "max-hashtag-length",
+				Usage:   "skip topics longer than this",
+				Sources: cli.EnvVars("MAX_HASHTAG_LENGTH"),
+				Value:   24,
+			},
+			&cli.StringFlag{
+				Name:    "spam-rules",
+				Usage:   "JSON file with the spam classifier's signals and threshold, built-in rules when unset",
+				Sources: cli.EnvVars("SPAM_RULES"),
+			},
+			&cli.StringFlag{
+				Name:    "campaigns-file",
+				Usage:   "JSON list of campaigns (name, language, topic, hashtag, interval) to run instead of the Go default",
+				Sources: cli.EnvVars("CAMPAIGNS_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "gitea-url",
+				Usage:   "Gitea/Forgejo instance to search as well, e.g. https://codeberg.org",
+				Sources: cli.EnvVars("GITEA_URL"),
+			},
+			&cli.StringFlag{
+				Name:    "gitea-token",
+				Sources: cli.EnvVars("GITEA_TOKEN"),
+			},
+			&cli.StringFlag{
+				Name:    "gitlab-url",
+				Usage:   "GitLab instance to list Go projects from, e.g. https://gitlab.com",
+				Sources: cli.EnvVars("GITLAB_URL"),
+			},
+			&cli.StringFlag{
+				Name:    "gitlab-token",
+				Sources: cli.EnvVars("GITLAB_TOKEN"),
+			},
+			&cli.StringFlag{
+				Name:    "curated-list",
+				Usage:   "GitHub repo (owner/name) of an awesome list to feature repos from, e.g. avelino/awesome-go",
+				Sources: cli.EnvVars("CURATED_LIST"),
+			},
+			&cli.StringFlag{
+				Name:    "curated-file",
+				Usage:   "local copy of the awesome list's README, read instead of fetching curated-list",
+				Sources: cli.EnvVars("CURATED_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "github-response-cache",
+				Usage:   "where to keep GitHub responses for conditional requests: memory, s3 or off",
+				Sources: cli.EnvVars("GITHUB_RESPONSE_CACHE"),
+				Value:   provider.ResponseCacheMemory,
+			},
+			&cli.StringSliceFlag{
+				Name:    "release-repos",
+				Usage:   "GitHub repos (owner/name) to announce new releases of",
+				Sources: cli.EnvVars("RELEASE_REPOS"),
+			},
+			&cli.BoolFlag{
+				Name:    "release-featured",
+				Usage:   "announce new releases of every repo the bot has posted about",
+				Sources: cli.EnvVars("RELEASE_FEATURED"),
+			},
+			&cli.DurationFlag{
+				Name:    "release-interval",
+				Usage:   "how often to check for new releases",
+				Sources: cli.EnvVars("RELEASE_INTERVAL"),
+				Value:   time.Hour,
+			},
+			&cli.StringFlag{
+				Name:    "submissions-token",
+				Usage:   "bearer token for POST /submissions on the stats server, the endpoint is off when unset",
+				Sources: cli.EnvVars("SUBMISSIONS_TOKEN"),
+			},
+			&cli.BoolFlag{
+				Name:    "moderate",
+				Usage:   "queue posts for approval on the stats server's /admin page instead of posting them; release announcements aren't moderated",
+				Sources: cli.EnvVars("MODERATE"),
+			},
+			&cli.DurationFlag{
+				Name:    "moderation-expiry",
+				Usage:   "how long a post waits for approval before it's dropped",
+				Sources: cli.EnvVars("MODERATION_EXPIRY"),
+				Value:   moderation.DefaultExpiry,
+			},
+			&cli.StringFlag{
+				Name:    "admin-token",
+				Usage:   "password for the /admin page, required with moderate",
+				Sources: cli.EnvVars("ADMIN_TOKEN"),
 			},
 			&cli.StringFlag{
 				Name:    "stats-port",
@@ -74,43 +274,124 @@ func main() {
 			},
 		},
 
+		Commands: []*cli.Command{
+			optOutCommand(),
+		},
+
 		Action: func(ctx context.Context, c *cli.Command) error {
-			// Initialize S3 client
-			mc, err := minio.New(c.String("aws-endpoint"), &minio.Options{
-				Creds:  credentials.NewStaticV4(c.String("aws-access-key-id"), c.String("aws-secret-key"), ""),
-				Secure: true,
-			})
+			app := provider.AppCredentials{
+				AppID:          c.Int64("github-app-id"),
+				InstallationID: c.Int64("github-app-installation-id"),
+				PrivateKey:     []byte(c.String("github-app-private-key")),
+			}
+			switch {
+			case c.String("bluesky-app-key") == "":
+				return errors.New("bluesky-app-key is required")
+			case app.AppID != 0 && (app.InstallationID == 0 || len(app.PrivateKey) == 0):
+				return errors.New("github-app-id needs github-app-installation-id and github-app-private-key")
+			case app.AppID == 0 && c.String("github-token") == "":
+				return errors.New("either github-token or the github-app-* flags are required")
+			case c.Bool("moderate") && c.String("admin-token") == "":
+				return errors.New("moderate needs admin-token to approve posts")
+			case c.Duration("trend-window") > 0 && c.Duration("trend-window") < 24*time.Hour:
+				return errors.New("trend-window must be at least a day, snapshots are taken daily")
+			}
+
+			mc, err := newMinioClient(ctx, c)
 			if err != nil {
-				return fmt.Errorf("failed to initialize minio client: %v", err)
+				return err
 			}
 
-			exists, err := mc.BucketExists(ctx, cacheBucket)
+			pol, err := policy.Load(c.String("policy-file"))
 			if err != nil {
-				return fmt.Errorf("failed to check bucket: %w", err)
+				return err
 			}
-			if !exists {
-				if err := mc.MakeBucket(ctx, cacheBucket, minio.MakeBucketOptions{}); err != nil {
-					return fmt.Errorf("failed to create bucket: %w", err)
-				}
+
+			classifier, err := spam.Load(c.String("spam-rules"))
+			if err != nil {
+				return err
+			}
+
+			campaigns, err := content.LoadCampaigns(c.String("campaigns-file"))
+			if err != nil {
+				return err
+			}
+
+			tagMap, err := hashtagMap(c.StringSlice("hashtag-map"))
+			if err != nil {
+				return err
+			}
+
+			weights, err := config.ParseWeights(c.StringSlice("score-weights"))
+			if err != nil {
+				return err
 			}
 
-			config := cmd.Config{
-				Handle:      blueskyHandle,
-				AppKey:      c.String("bluesky-app-key"),
-				CacheBucket: cacheBucket,
-				GitHubToken: c.String("github-token"),
+			rates := provider.NewRateTracker()
+
+			cfg := cmd.Config{
+				Handle:              blueskyHandle,
+				AppKey:              c.String("bluesky-app-key"),
+				CacheBucket:         cacheBucket,
+				GitHubToken:         c.String("github-token"),
+				GitHubApp:           app,
+				GitHubResponseCache: c.String("github-response-cache"),
+				GiteaURL:            c.String("gitea-url"),
+				GiteaToken:          c.String("gitea-token"),
+				GitLabURL:           c.String("gitlab-url"),
+				GitLabToken:         c.String("gitlab-token"),
+				CuratedList:         c.String("curated-list"),
+				CuratedFile:         c.String("curated-file"),
+				ReleaseRepos:        c.StringSlice("release-repos"),
+				ReleaseFeatured:     c.Bool("release-featured"),
+				ReleaseInterval:     c.Duration("release-interval"),
+				License: config.LicensePolicy{
+					Required:   c.Bool("require-license"),
+					Allowed:    c.StringSlice("allowed-licenses"),
+					ShowInPost: c.Bool("show-license"),
+				},
+				Policy:      pol,
+				Campaigns:   campaigns,
+				Spam:        classifier,
+				Weights:     weights,
+				PickTop:     c.Bool("pick-top"),
+				TrendWindow: c.Duration("trend-window"),
+				Hashtags: config.Hashtags{
+					Map:       tagMap,
+					Max:       c.Int("max-hashtags"),
+					MaxLength: c.Int("max-hashtag-length"),
+				},
+				Rates: rates,
+				Quality: config.Quality{
+					MinStars:           c.Int("min-stars"),
+					MaxStars:           c.Int("max-stars"),
+					MinSizeKB:          c.Int("min-size-kb"),
+					ExcludeForks:       c.Bool("exclude-forks"),
+					ExcludeTemplates:   c.Bool("exclude-templates"),
+					ExcludeMirrors:     c.Bool("exclude-mirrors"),
+					RequireDescription: c.Bool("require-description"),
+					RequireGoModule:    c.Bool("require-go-mod"),
+				},
 			}
 
 			addr := "0.0.0.0" + c.String("stats-port")
 
-			statsSrv := stats.NewServer(addr, stats.MinioProvider(mc, cacheBucket))
+			cacheClient := cache.NewClientS3(mc, cacheBucket)
+			statsSrv := stats.NewServer(addr, stats.MinioProvider(mc, cacheBucket)).
+				WithRateLimits(githubRateLimits(rates)).
+				WithSubmissions(c.String("submissions-token"), submission.NewQueue(&cacheClient))
+			if c.Bool("moderate") {
+				// the admin page and the posting loop share the queue and its lock
+				cfg.Moderation = moderation.NewQueue(&cacheClient, c.Duration("moderation-expiry"))
+				statsSrv.WithModeration(c.String("admin-token"), cfg.Moderation)
+			}
 			go func() {
 				if err := statsSrv.ListenAndServe(ctx); err != nil {
 					slog.Error("stats server error", "error", err)
 				}
 			}()
 
-			return cmd.RunWithReconnect(ctx, mc, config)
+			return cmd.RunWithReconnect(ctx, mc, cfg)
 		},
 	}
 
@@ -125,3 +406,54 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// newMinioClient initializes the S3 client and makes sure the cache bucket exists.
+func newMinioClient(ctx context.Context, c *cli.Command) (*minio.Client, error) {
+	mc, err := minio.New(c.String("aws-endpoint"), &minio.Options{
+		Creds:  credentials.NewStaticV4(c.String("aws-access-key-id"), c.String("aws-secret-key"), ""),
+		Secure: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize minio client: %v", err)
+	}
+
+	exists, err := mc.BucketExists(ctx, cacheBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bucket: %w", err)
+	}
+	if !exists {
+		if err := mc.MakeBucket(ctx, cacheBucket, minio.MakeBucketOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to create bucket: %w", err)
+		}
+	}
+	return mc, nil
+}
+
+// hashtagMap merges topic=tag pairs into the default mapping.
+func hashtagMap(pairs []string) (map[string]string, error) {
+	m := config.DefaultHashtagMap()
+	for _, pair := range pairs {
+		topic, tag, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(topic) == "" {
+			return nil, fmt.Errorf("invalid hashtag mapping %q, expected topic=tag", pair)
+		}
+		m[strings.ToLower(strings.TrimSpace(topic))] = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+	}
+	return m, nil
+}
+
+// githubRateLimits adapts the provider's rate tracker for the stats page.
+func githubRateLimits(rates *provider.RateTracker) stats.RateLimitProvider {
+	return func() []stats.RateLimit {
+		var out []stats.RateLimit
+		for category, r := range rates.Snapshot() {
+			out = append(out, stats.RateLimit{
+				Category:  category,
+				Limit:     r.Limit,
+				Remaining: r.Remaining,
+				Reset:     r.Reset.Time,
+			})
+		}
+		return out
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/till/golangoss-bluesky/internal/cache"
+	"github.com/till/golangoss-bluesky/internal/optout"
+	"github.com/urfave/cli/v3"
+)
+
+// optOutCommand manages the registry of maintainers who don't want their
+// repos featured.
+func optOutCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "optout",
+		Usage: "manage maintainers who asked not to be featured",
+		Commands: []*cli.Command{
+			{
+				Name:      "add",
+				Usage:     "opt GitHub logins out",
+				ArgsUsage: "<login>...",
+				Action: withOptOutStore(1, func(ctx context.Context, s optout.Store, logins []string) error {
+					for _, l := range logins {
+						if err := s.Add(ctx, l, "cli"); err != nil {
+							return fmt.Errorf("add %s: %w", l, err)
+						}
+					}
+					return nil
+				}),
+			},
+			{
+				Name:      "remove",
+				Usage:     "opt GitHub logins back in",
+				ArgsUsage: "<login>...",
+				Action: withOptOutStore(1, func(ctx context.Context, s optout.Store, logins []string) error {
+					for _, l := range logins {
+						if err := s.Remove(ctx, l); err != nil {
+							return fmt.Errorf("remove %s: %w", l, err)
+						}
+					}
+					return nil
+				}),
+			},
+			{
+				Name:  "list",
+				Usage: "list opted out GitHub logins",
+				Action: withOptOutStore(0, func(ctx context.Context, s optout.Store, _ []string) error {
+					logins, err := s.List(ctx)
+					if err != nil {
+						return err
+					}
+					for _, l := range logins {
+						fmt.Println(l)
+					}
+					return nil
+				}),
+			},
+		},
+	}
+}
+
+// withOptOutStore connects to the cache bucket and hands the store and the
+// command's arguments to fn, after checking there are at least minArgs.
+func withOptOutStore(minArgs int, fn func(ctx context.Context, s optout.Store, args []string) error) cli.ActionFunc {
+	return func(ctx context.Context, c *cli.Command) error {
+		if c.NArg() < minArgs {
+			return errors.New("at least one login required")
+		}
+
+		mc, err := newMinioClient(ctx, c)
+		if err != nil {
+			return err
+		}
+		cacheClient := cache.NewClientS3(mc, cacheBucket)
+		return fn(ctx, optout.NewStore(&cacheClient), c.Args().Slice())
+	}
+}
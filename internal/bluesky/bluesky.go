@@ -21,9 +21,32 @@ type Client struct {
 	Client *bk.Client
 }
 
+// PostInput is what a repo post says.
+type PostInput struct {
+	Title       string
+	Description string
+	URL         string // the repo, linked from the title
+	Author      PostAuthor
+	Stars       string // star count and the like, shown after the author
+	Module      string // Go module path, linked to its pkg.go.dev page
+	Hashtags    string
+}
+
+// PostAuthor credits the repo's author.
+type PostAuthor struct {
+	// Name is the "@login" shown, or the "@handle" when DID is set. The
+	// author is left out when it's empty.
+	Name string
+	DID  string // mentions the author
+	URL  string // links the author when not mentioned, their GitHub profile when empty
+}
+
 // PostRecord constructs a post record with a facet. To get there, it will find the
-// position of the URL inside the text and attaches it to the post.
-func PostRecord(title, description, url, author, stargazers, hashtags string) *bsky.FeedPost {
+// position of the URL inside the text and attaches it to the post. The author
+// is mentioned when their DID is set, otherwise linked to their profile. A Go
+// module path adds a line linking to its pkg.go.dev page.
+func PostRecord(in PostInput) *bsky.FeedPost {
+	title, description, author, module, hashtags := in.Title, in.Description, in.Author.Name, in.Module, in.Hashtags
 	text := title
 
 	var startAuthor int64 = -1
@@ -33,21 +56,42 @@ func PostRecord(title, description, url, author, stargazers, hashtags string) *b
 		startAuthor = int64(strings.Index(text, " by @")) + 4
 	}
 
-	if len(stargazers) > 0 {
-		text += fmt.Sprintf(" (%s)", stargazers)
+	if len(in.Stars) > 0 {
+		text += fmt.Sprintf(" (%s)", in.Stars)
+	}
+
+	moduleText, moduleLine := "", ""
+	if len(module) > 0 {
+		moduleText = "pkg.go.dev/" + module
+		moduleLine = "\n\n📦 " + moduleText
+	}
+
+	// hashtags go last, drop some when even the description-less post
+	// would be too long
+	hashtags = fitHashtags(hashtags, postLimit-len(text)-len(moduleLine)-len("\n\n"))
+	tail := len(moduleLine)
+	if len(hashtags) > 0 {
+		tail += len("\n\n") + len(hashtags)
 	}
 
-	// 300 = limit + '#go'
-	if len(text) < (300-3) && len(description) > 0 {
+	if len(description) > 0 {
 		// poor version of normalize
 		description = strings.Join(strings.Fields(description), " ")
-		if len(description) > 150 {
-			text += "\n\n" + description[0:147] + "..."
-		} else {
+		limit := min(150, postLimit-len(text)-tail-len("\n\n"))
+		if len(description) > limit {
+			description = truncate(description, limit-3) + "..."
+		}
+		if limit > 3 {
 			text += "\n\n" + description
 		}
 	}
 
+	var startModule int64 = -1
+	if len(moduleLine) > 0 {
+		text += moduleLine
+		startModule = int64(len(text) - len(moduleText))
+	}
+
 	if len(hashtags) > 0 {
 		text += "\n\n" + hashtags
 	}
@@ -58,20 +102,37 @@ func PostRecord(title, description, url, author, stargazers, hashtags string) *b
 	facets = append(facets, addFacet(
 		startRepoURL,
 		startRepoURL+int64(len(title)),
-		addLinkFeature(url)))
+		addLinkFeature(in.URL)))
 
 	if startAuthor > 0 {
+		var feature any
+		switch {
+		case in.Author.DID != "":
+			feature = addMentionFeature(in.Author.DID)
+		case in.Author.URL != "":
+			feature = addLinkFeature(in.Author.URL)
+		default:
+			feature = addLinkFeature("https://github.com/" + author[1:])
+		}
 		facets = append(facets, addFacet(
 			startAuthor,
 			startAuthor+int64(len(author)),
-			addLinkFeature("https://github.com/"+author[1:]),
+			feature,
+		))
+	}
+
+	if startModule > 0 {
+		facets = append(facets, addFacet(
+			startModule,
+			startModule+int64(len(moduleText)),
+			addLinkFeature("https://pkg.go.dev/"+module),
 		))
 	}
 
 	if len(hashtags) > 0 {
 		allTags := strings.Fields(hashtags)
 
-		startHashTag := int64(strings.Index(text, hashtags))
+		startHashTag := int64(strings.LastIndex(text, hashtags))
 
 		for _, t := range allTags {
 			slog.Debug(t)
@@ -96,6 +157,15 @@ func PostRecord(title, description, url, author, stargazers, hashtags string) *b
 	}
 }
 
+// fitHashtags drops tags from the end of hashtags until it's at most n bytes.
+func fitHashtags(hashtags string, n int) string {
+	tags := strings.Fields(hashtags)
+	for len(tags) > 0 && len(strings.Join(tags, " ")) > n {
+		tags = tags[:len(tags)-1]
+	}
+	return strings.Join(tags, " ")
+}
+
 // build structure for the facet (enables linking)
 func addFacet(start, end int64, feature any) *bsky.RichtextFacet {
 	facet := &bsky.RichtextFacet{
@@ -116,7 +186,9 @@ func addFacet(start, end int64, feature any) *bsky.RichtextFacet {
 			RichtextFacet_Tag: f,
 		})
 	case *bsky.RichtextFacet_Mention:
-		panic("mention not supported")
+		facet.Features = append(facet.Features, &bsky.RichtextFacet_Features_Elem{
+			RichtextFacet_Mention: f,
+		})
 	default:
 		panic("unknown type")
 	}
@@ -137,6 +209,13 @@ func addTagFeature(tag string) *bsky.RichtextFacet_Tag {
 	}
 }
 
+// build structure for the feature (mentioned account)
+func addMentionFeature(did string) *bsky.RichtextFacet_Mention {
+	return &bsky.RichtextFacet_Mention{
+		Did: did,
+	}
+}
+
 // Post creates a post on BlueSky
 func (c *Client) Post(ctx context.Context, post *bsky.FeedPost) error {
 	return c.Client.CustomCall(func(api *xrpc.Client) error {
@@ -157,6 +236,75 @@ func (c *Client) Post(ctx context.Context, post *bsky.FeedPost) error {
 	})
 }
 
+// ResolveHandle returns the DID of the account behind handle.
+func (c *Client) ResolveHandle(ctx context.Context, handle string) (string, error) {
+	var did string
+	err := c.Client.CustomCall(func(api *xrpc.Client) error {
+		out, err := atproto.IdentityResolveHandle(ctx, api, strings.TrimPrefix(handle, "@"))
+		if err != nil {
+			return err
+		}
+		did = out.Did
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("resolve handle %s: %w", handle, err)
+	}
+	return did, nil
+}
+
+// VerifiedHandle returns the handle of the account with did. The handle has
+// to resolve back to did, otherwise it isn't the account's and an error is
+// returned.
+func (c *Client) VerifiedHandle(ctx context.Context, did string) (string, error) {
+	return verifiedHandle(ctx, c, did)
+}
+
+// ProfileHandle returns the handle the profile of the account with did
+// claims, unverified.
+func (c *Client) ProfileHandle(ctx context.Context, did string) (string, error) {
+	var handle string
+	err := c.Client.CustomCall(func(api *xrpc.Client) error {
+		profile, err := bsky.ActorGetProfile(ctx, api, did)
+		if err != nil {
+			return err
+		}
+		handle = profile.Handle
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("profile of %s: %w", did, err)
+	}
+	return handle, nil
+}
+
+// identity looks up handles and DIDs, see Client.
+type identity interface {
+	ResolveHandle(ctx context.Context, handle string) (string, error)
+	ProfileHandle(ctx context.Context, did string) (string, error)
+}
+
+// verifiedHandle checks that the handle did's profile claims resolves
+// back to did.
+func verifiedHandle(ctx context.Context, id identity, did string) (string, error) {
+	handle, err := id.ProfileHandle(ctx, did)
+	if err != nil {
+		return "", err
+	}
+	if handle == "" || handle == "handle.invalid" {
+		return "", fmt.Errorf("%s has no valid handle", did)
+	}
+
+	back, err := id.ResolveHandle(ctx, handle)
+	if err != nil {
+		return "", err
+	}
+	if back != did {
+		return "", fmt.Errorf("handle %s belongs to %s, not %s", handle, back, did)
+	}
+	return handle, nil
+}
+
 func handleError(ctx context.Context, err error) error {
 	if err == nil {
 		return nil
@@ -1,55 +1,69 @@
 package bluesky_test
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/till/golangoss-bluesky/internal/bluesky"
 )
 
 type testCase struct {
-	Title          string
-	Description    string
-	URL            string
-	Author         string
-	Stargazers     string
-	Tag            string
+	bluesky.PostInput
 	ExpectedFacets int
 }
 
 func TestPostRecord(t *testing.T) {
 	testCases := []testCase{
 		{
-			Title:          "simple",
-			Description:    "description",
-			URL:            "https://github.com/user/repo",
-			Author:         "@user",
-			Stargazers:     "1 ⭐️",
-			Tag:            "#go",
+			PostInput: bluesky.PostInput{
+				Title:       "simple",
+				Description: "description",
+				URL:         "https://github.com/user/repo",
+				Author:      bluesky.PostAuthor{Name: "@user"},
+				Stars:       "1 ⭐️",
+				Hashtags:    "#go",
+			},
 			ExpectedFacets: 3,
 		},
 		{
-			Title:          "extra-long-description",
-			Description:    "description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description",
-			URL:            "https://github.com/org/repo",
-			Author:         "",
-			Stargazers:     "1 ⭐️",
-			Tag:            "#go",
+			PostInput: bluesky.PostInput{
+				Title:       "extra-long-description",
+				Description: "description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description, description",
+				URL:         "https://github.com/org/repo",
+				Stars:       "1 ⭐️",
+				Hashtags:    "#go",
+			},
 			ExpectedFacets: 2,
 		},
 		{
-			Title:          "Short",
-			URL:            "https://github.com/s/s",
-			Author:         "",
-			Stargazers:     "0 ⭐️",
-			Tag:            "",
+			PostInput: bluesky.PostInput{
+				Title:       "module",
+				Description: "description, description, description, description, description, description, description, description, description, description, description, description, description, description, description",
+				URL:         "https://github.com/org/repo",
+				Author:      bluesky.PostAuthor{Name: "@org"},
+				Stars:       "1 ⭐️",
+				Module:      "github.com/org/repo/v2",
+				Hashtags:    "#go",
+			},
+			ExpectedFacets: 4,
+		},
+		{
+			PostInput: bluesky.PostInput{
+				Title: "Short",
+				URL:   "https://github.com/s/s",
+				Stars: "0 ⭐️",
+			},
 			ExpectedFacets: 1,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.Title, func(t *testing.T) {
-			record := bluesky.PostRecord(tc.Title, tc.Description, tc.URL, tc.Author, tc.Stargazers, tc.Tag)
+			record := bluesky.PostRecord(tc.PostInput)
 			assert.NotNil(t, record)
 
 			assert.NotEmpty(t, record.CreatedAt)
@@ -59,3 +73,175 @@ func TestPostRecord(t *testing.T) {
 		})
 	}
 }
+
+func TestReleaseRecord(t *testing.T) {
+	notes := "## What's Changed\n" +
+		"* Add [retries](https://github.com/o/r/pull/1) by @someone\n" +
+		"* Fix `nil` panic\n\n" +
+		"**Full Changelog**: https://github.com/o/r/compare/v1.0.0...v1.1.0\n"
+
+	record := bluesky.ReleaseRecord("o/r", "v1.1.0", "v1.1.0", notes, "https://github.com/o/r/releases/tag/v1.1.0", "#go")
+	assert.Equal(t, "🚀 o/r v1.1.0 released\n\n• Add retries by @someone\n• Fix nil panic\n\n#go", record.Text)
+	require.Len(t, record.Facets, 2)
+	link := record.Facets[0]
+	assert.Equal(t, "o/r v1.1.0", record.Text[link.Index.ByteStart:link.Index.ByteEnd])
+	tag := record.Facets[1]
+	assert.Equal(t, "#go", record.Text[tag.Index.ByteStart:tag.Index.ByteEnd])
+
+	long := strings.Repeat("- a very long changelog line with lots of detail\n", 20)
+	record = bluesky.ReleaseRecord("o/r", "v2.0.0", "Big one", long, "https://github.com/o/r/releases/tag/v2.0.0", "#go")
+	assert.LessOrEqual(t, len(record.Text), 300)
+	assert.Contains(t, record.Text, "released: Big one")
+	assert.True(t, strings.HasSuffix(record.Text, "\n\n#go"))
+}
+
+func TestPostRecord_ModuleFacet(t *testing.T) {
+	record := bluesky.PostRecord(bluesky.PostInput{
+		Title:       "repo",
+		Description: "does things",
+		URL:         "https://github.com/org/repo",
+		Stars:       "1 ⭐️",
+		Module:      "github.com/org/repo",
+		Hashtags:    "#go",
+	})
+	assert.Equal(t, "repo (1 ⭐️)\n\ndoes things\n\n📦 pkg.go.dev/github.com/org/repo\n\n#go", record.Text)
+	require.Len(t, record.Facets, 3)
+
+	f := record.Facets[1]
+	assert.Equal(t, "pkg.go.dev/github.com/org/repo", record.Text[f.Index.ByteStart:f.Index.ByteEnd])
+	assert.Equal(t, "https://pkg.go.dev/github.com/org/repo", f.Features[0].RichtextFacet_Link.Uri)
+}
+
+func TestPostRecord_FitsHashtags(t *testing.T) {
+	title := strings.Repeat("t", 100)
+	hashtags := "#go #" + strings.Repeat("a", 60) + " #" + strings.Repeat("b", 60) + " #" + strings.Repeat("c", 60)
+	record := bluesky.PostRecord(bluesky.PostInput{
+		Title:       title,
+		Description: "some description",
+		URL:         "https://github.com/o/r",
+		Author:      bluesky.PostAuthor{Name: "@someone"},
+		Stars:       "⭐️ 12",
+		Module:      "github.com/o/r",
+		Hashtags:    hashtags,
+	})
+
+	assert.LessOrEqual(t, len(record.Text), 300)
+	assert.Contains(t, record.Text, "#go #"+strings.Repeat("a", 60))
+	assert.NotContains(t, record.Text, "#"+strings.Repeat("c", 60))
+
+	tags := 0
+	for _, f := range record.Facets {
+		if tag := f.Features[0].RichtextFacet_Tag; tag != nil {
+			assert.Equal(t, "#"+tag.Tag, record.Text[f.Index.ByteStart:f.Index.ByteEnd])
+			tags++
+		}
+	}
+	assert.Equal(t, len(strings.Fields(record.Text[strings.LastIndex(record.Text, "#go"):])), tags)
+}
+
+func TestPostRecord_MentionsAuthor(t *testing.T) {
+	record := bluesky.PostRecord(bluesky.PostInput{
+		Title: "repo",
+		URL:   "https://github.com/org/repo",
+		Author: bluesky.PostAuthor{
+			Name: "@maintainer.bsky.social",
+			DID:  "did:plc:abc123",
+			URL:  "https://github.com/org",
+		},
+		Stars:    "1 ⭐️",
+		Hashtags: "#go",
+	})
+	require.Len(t, record.Facets, 3)
+
+	f := record.Facets[1]
+	assert.Equal(t, "@maintainer.bsky.social", record.Text[f.Index.ByteStart:f.Index.ByteEnd])
+	require.NotNil(t, f.Features[0].RichtextFacet_Mention)
+	assert.Nil(t, f.Features[0].RichtextFacet_Link)
+	assert.Equal(t, "did:plc:abc123", f.Features[0].RichtextFacet_Mention.Did)
+}
+
+func TestEditText(t *testing.T) {
+	record := bluesky.PostRecord(bluesky.PostInput{
+		Title:       "repo",
+		Description: "does things",
+		URL:         "https://github.com/org/repo",
+		Author:      bluesky.PostAuthor{Name: "@octo", URL: "https://github.com/octo"},
+		Stars:       "1 ⭐️",
+		Hashtags:    "#go #cli",
+	})
+
+	require.NoError(t, bluesky.EditText(record, "Check out repo by @octo!\r\n\r\nIt does things.\r\n\r\n#go"))
+	assert.Equal(t, "Check out repo by @octo!\n\nIt does things.\n\n#go", record.Text)
+
+	var got []string
+	for _, f := range record.Facets {
+		got = append(got, record.Text[f.Index.ByteStart:f.Index.ByteEnd])
+	}
+	assert.Equal(t, []string{"repo", "@octo", "#go"}, got, "the #cli facet was edited away")
+	assert.Equal(t, "https://github.com/org/repo", record.Facets[0].Features[0].RichtextFacet_Link.Uri)
+
+	assert.Error(t, bluesky.EditText(record, "  "))
+	assert.Error(t, bluesky.EditText(record, strings.Repeat("x", 301)))
+	assert.Equal(t, "Check out repo by @octo!\n\nIt does things.\n\n#go", record.Text, "failed edits keep the text")
+}
+
+// fakeIdentity answers lookups from maps, unknown entries fail.
+type fakeIdentity struct {
+	dids     map[string]string // handle to DID
+	profiles map[string]string // DID to the handle its profile claims
+}
+
+func (f fakeIdentity) ResolveHandle(_ context.Context, handle string) (string, error) {
+	if did, ok := f.dids[handle]; ok {
+		return did, nil
+	}
+	return "", errors.New("unable to resolve handle")
+}
+
+func (f fakeIdentity) ProfileHandle(_ context.Context, did string) (string, error) {
+	if handle, ok := f.profiles[did]; ok {
+		return handle, nil
+	}
+	return "", errors.New("profile not found")
+}
+
+func TestVerifyHandle(t *testing.T) {
+	id := fakeIdentity{
+		dids: map[string]string{
+			"octo.bsky.social": "did:plc:octo",
+			"taken.example":    "did:plc:someone-else",
+		},
+		profiles: map[string]string{
+			"did:plc:octo":     "octo.bsky.social",
+			"did:plc:spoofed":  "taken.example",
+			"did:plc:gone":     "gone.example",
+			"did:plc:invalid":  "handle.invalid",
+			"did:plc:nohandle": "",
+		},
+	}
+
+	testCases := []struct {
+		name string
+		did  string
+		want string
+	}{
+		{"handle resolves back", "did:plc:octo", "octo.bsky.social"},
+		{"handle belongs to someone else", "did:plc:spoofed", ""},
+		{"handle doesn't resolve", "did:plc:gone", ""},
+		{"invalid handle", "did:plc:invalid", ""},
+		{"no handle", "did:plc:nohandle", ""},
+		{"no profile", "did:plc:unknown", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := bluesky.VerifyHandle(context.Background(), id, tc.did)
+			if tc.want == "" {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package bluesky
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+)
+
+// EditText replaces the text of post. Facets are byte ranges of the text,
+// so each one is moved to where its text occurs in the new text, in the
+// order they had; facets whose text was edited away are dropped. Line
+// breaks are normalized to "\n", as submitted by a browser form.
+func EditText(post *bsky.FeedPost, text string) error {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return errors.New("empty post")
+	}
+	if n := utf8.RuneCountInString(text); n > postLimit {
+		return fmt.Errorf("post has %d characters, at most %d fit", n, postLimit)
+	}
+
+	old := post.Text
+	facets := slices.DeleteFunc(slices.Clone(post.Facets), func(f *bsky.RichtextFacet) bool { return f.Index == nil })
+	slices.SortStableFunc(facets, func(a, b *bsky.RichtextFacet) int {
+		return int(a.Index.ByteStart - b.Index.ByteStart)
+	})
+
+	kept := make([]*bsky.RichtextFacet, 0, len(facets))
+	from := 0
+	for _, f := range facets {
+		start, end := int(f.Index.ByteStart), int(f.Index.ByteEnd)
+		if start < 0 || end > len(old) || start >= end {
+			continue
+		}
+		frag := old[start:end]
+		i := strings.Index(text[from:], frag)
+		if i < 0 {
+			continue
+		}
+		start = from + i
+		from = start + len(frag)
+		kept = append(kept, &bsky.RichtextFacet{
+			Index:    &bsky.RichtextFacet_ByteSlice{ByteStart: int64(start), ByteEnd: int64(from)},
+			Features: f.Features,
+		})
+	}
+
+	post.Text, post.Facets = text, kept
+	return nil
+}
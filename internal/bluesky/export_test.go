@@ -0,0 +1,5 @@
+package bluesky
+
+var VerifyHandle = verifiedHandle
+
+var OptOutRequests = optOutRequests
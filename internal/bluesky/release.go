@@ -0,0 +1,119 @@
+package bluesky
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+)
+
+const (
+	// postLimit is the max length of a post's text.
+	postLimit = 300
+	// releaseExcerptLines is how many lines of the changelog make it into
+	// the post.
+	releaseExcerptLines = 3
+	// releaseNameLimit keeps a long release name from crowding out the
+	// changelog.
+	releaseNameLimit = 100
+)
+
+var markdownLink = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+
+// ReleaseRecord constructs the post announcing a release: repo and tag,
+// linked to url, the release name when it says more than the tag, and the
+// first lines of the changelog trimmed to fit the post.
+func ReleaseRecord(repo, tag, name, notes, url, hashtags string) *bsky.FeedPost {
+	headline := repo + " " + tag
+	text := "🚀 " + headline + " released"
+	startHeadline := int64(len("🚀 "))
+
+	if name = strings.TrimSpace(name); name != "" && name != tag {
+		if len(name) > releaseNameLimit {
+			name = truncate(name, releaseNameLimit-3) + "..."
+		}
+		text += ": " + name
+	}
+
+	tail := ""
+	if len(hashtags) > 0 {
+		tail = "\n\n" + hashtags
+	}
+
+	if excerpt := changelogExcerpt(notes); excerpt != "" {
+		budget := postLimit - len(text) - len(tail) - len("\n\n")
+		if len(excerpt) > budget {
+			excerpt = truncate(excerpt, budget-len("...")) + "..."
+		}
+		if budget > len("...") {
+			text += "\n\n" + excerpt
+		}
+	}
+	text += tail
+
+	facets := []*bsky.RichtextFacet{
+		addFacet(startHeadline, startHeadline+int64(len(headline)), addLinkFeature(url)),
+	}
+
+	if len(hashtags) > 0 {
+		startHashTag := int64(strings.LastIndex(text, hashtags))
+		for _, t := range strings.Fields(hashtags) {
+			facets = append(facets, addFacet(
+				startHashTag,
+				startHashTag+int64(len(t)),
+				addTagFeature(t[1:]),
+			))
+			startHashTag += int64(len(t)) + 1
+		}
+	}
+
+	return &bsky.FeedPost{
+		Text:      text,
+		CreatedAt: time.Now().Format(time.RFC3339),
+		Langs:     []string{"en-UK"},
+		Facets:    facets,
+	}
+}
+
+// changelogExcerpt returns the first lines of a markdown release body with
+// headings, list markers and link targets removed. The "Full Changelog"
+// footer GitHub generates is dropped, it's just a link.
+func changelogExcerpt(notes string) string {
+	var lines []string
+	for line := range strings.Lines(notes) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.Contains(line, "Full Changelog") {
+			continue
+		}
+		line = strings.TrimLeft(line, "-*+ ")
+		line = markdownLink.ReplaceAllString(line, "$1")
+		line = strings.ReplaceAll(line, "**", "")
+		line = strings.ReplaceAll(line, "`", "")
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+
+		lines = append(lines, "• "+line)
+		if len(lines) == releaseExcerptLines {
+			break
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
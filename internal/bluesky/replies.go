@@ -0,0 +1,131 @@
+package bluesky
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/bluesky-social/indigo/xrpc"
+)
+
+// optOutPattern matches "opt out", "opt-out" and "optout" in a reply.
+var optOutPattern = regexp.MustCompile(`(?i)\bopt[\s-]?out\b`)
+
+// feedGetPostsLimit is the most URIs app.bsky.feed.getPosts accepts.
+const feedGetPostsLimit = 25
+
+// OptOutReply is a reply asking the bot to stop featuring the maintainer
+// the replied-to post credited.
+type OptOutReply struct {
+	URI         string // the reply
+	Handle      string // who replied
+	DID         string // who replied, for owners that link a DID
+	GitHubLogin string // author credited in our post
+}
+
+// OptOutReplies returns the opt-out requests among the replies to our posts
+// indexed after since, along with when the newest notification looked at
+// was indexed, to pass as since next time. Notifications aren't marked as
+// seen, so the replies and mentions meant for a human stay unread.
+func (c *Client) OptOutReplies(ctx context.Context, since time.Time) ([]OptOutReply, time.Time, error) {
+	var (
+		out    []OptOutReply
+		newest = since
+	)
+
+	err := c.Client.CustomCall(func(api *xrpc.Client) error {
+		res, err := bsky.NotificationListNotifications(ctx, api, "", 50, false, "")
+		if err != nil {
+			return handleError(ctx, err)
+		}
+
+		requests, parents, latest := optOutRequests(res.Notifications, since)
+		newest = latest
+
+		logins := map[string]string{}
+		for start := 0; start < len(parents); start += feedGetPostsLimit {
+			end := min(start+feedGetPostsLimit, len(parents))
+			posts, err := bsky.FeedGetPosts(ctx, api, parents[start:end])
+			if err != nil {
+				return handleError(ctx, err)
+			}
+			for _, p := range posts.Posts {
+				if fp, ok := p.Record.Val.(*bsky.FeedPost); ok {
+					logins[p.Uri] = creditedGitHubLogin(fp)
+				}
+			}
+		}
+
+		for i, r := range requests {
+			if r.GitHubLogin = logins[parents[i]]; r.GitHubLogin != "" {
+				out = append(out, r)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, since, err
+	}
+	return out, newest, nil
+}
+
+// optOutRequests returns the replies asking to opt out among the
+// notifications indexed after since, the URIs of the posts they reply to,
+// and when the newest notification was indexed.
+func optOutRequests(notifs []*bsky.NotificationListNotifications_Notification, since time.Time) (requests []OptOutReply, parents []string, newest time.Time) {
+	newest = since
+	for _, n := range notifs {
+		indexed, err := time.Parse(time.RFC3339, n.IndexedAt)
+		if err != nil || !indexed.After(since) {
+			continue
+		}
+		if indexed.After(newest) {
+			newest = indexed
+		}
+
+		if n.Reason != "reply" || n.Record == nil {
+			continue
+		}
+		post, ok := n.Record.Val.(*bsky.FeedPost)
+		if !ok || post.Reply == nil || post.Reply.Parent == nil {
+			continue
+		}
+		if !optOutPattern.MatchString(post.Text) {
+			continue
+		}
+		requests = append(requests, OptOutReply{URI: n.Uri, Handle: n.Author.Handle, DID: n.Author.Did})
+		parents = append(parents, post.Reply.Parent.Uri)
+	}
+	return requests, parents, newest
+}
+
+// creditedGitHubLogin returns the login of the GitHub profile the post's
+// author facet links to. When the author was mentioned instead, it's the
+// owner of the linked GitHub repo. Returns "" if there is neither.
+func creditedGitHubLogin(post *bsky.FeedPost) string {
+	const prefix = "https://github.com/"
+	owner := ""
+	for _, f := range post.Facets {
+		for _, feat := range f.Features {
+			if feat.RichtextFacet_Link == nil {
+				continue
+			}
+			uri := feat.RichtextFacet_Link.Uri
+			if !strings.HasPrefix(uri, prefix) {
+				continue
+			}
+			// repo links have an owner/name path, profiles just the login
+			login, _, isRepo := strings.Cut(strings.TrimPrefix(uri, prefix), "/")
+			switch {
+			case login == "":
+			case !isRepo:
+				return login
+			case owner == "":
+				owner = login
+			}
+		}
+	}
+	return owner
+}
@@ -0,0 +1,51 @@
+package bluesky_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/atproto"
+	"github.com/bluesky-social/indigo/api/bsky"
+	lexutil "github.com/bluesky-social/indigo/lex/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/till/golangoss-bluesky/internal/bluesky"
+)
+
+func notification(uri, reason, text, indexed string) *bsky.NotificationListNotifications_Notification {
+	post := &bsky.FeedPost{Text: text}
+	if reason == "reply" {
+		post.Reply = &bsky.FeedPost_ReplyRef{Parent: &atproto.RepoStrongRef{Uri: "at://bot/post/" + uri}}
+	}
+	return &bsky.NotificationListNotifications_Notification{
+		Uri:       uri,
+		Reason:    reason,
+		IndexedAt: indexed,
+		Author:    &bsky.ActorDefs_ProfileView{Handle: "maintainer.bsky.social", Did: "did:plc:maintainer"},
+		Record:    &lexutil.LexiconTypeDecoder{Val: post},
+	}
+}
+
+func TestOptOutRequests(t *testing.T) {
+	since := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	notifs := []*bsky.NotificationListNotifications_Notification{
+		notification("new", "reply", "please opt-out", "2026-10-01T14:00:00.000Z"),
+		notification("mention", "mention", "opt out", "2026-10-01T13:30:00.000Z"),
+		notification("chat", "reply", "nice project!", "2026-10-01T13:00:00.000Z"),
+		notification("handled", "reply", "opt out", "2026-10-01T12:00:00.000Z"),
+		notification("old", "reply", "optout", "2026-10-01T11:00:00.000Z"),
+	}
+
+	requests, parents, newest := bluesky.OptOutRequests(notifs, since)
+	assert.Equal(t, []bluesky.OptOutReply{
+		{URI: "new", Handle: "maintainer.bsky.social", DID: "did:plc:maintainer"},
+	}, requests)
+	assert.Equal(t, []string{"at://bot/post/new"}, parents)
+	assert.Equal(t, time.Date(2026, 10, 1, 14, 0, 0, 0, time.UTC), newest)
+
+	requests, _, newest = bluesky.OptOutRequests(notifs, newest)
+	assert.Empty(t, requests, "nothing new since the last look")
+	assert.Equal(t, time.Date(2026, 10, 1, 14, 0, 0, 0, time.UTC), newest)
+
+	requests, _, _ = bluesky.OptOutRequests(notifs, time.Time{})
+	assert.Len(t, requests, 3, "the first look takes all opt-out replies")
+}
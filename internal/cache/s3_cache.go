@@ -16,6 +16,11 @@ import (
 	"github.com/minio/minio-go/v7"
 )
 
+// NoExpiry is the expiry of entries meant to stay for good. The cleanup
+// routine deletes anything without an expiry, so they get one far in the
+// future.
+const NoExpiry = 100 * 365 * 24 * time.Hour
+
 // ClientS3 is a small cache that is backed by an S3-compatible store
 type ClientS3 struct {
 	mc                *minio.Client
@@ -108,3 +113,16 @@ func (c *ClientS3) Del(ctx context.Context, key string) error {
 		ForceDelete: true,
 	})
 }
+
+// List returns the keys starting with prefix. Expired entries are included
+// until the cleanup routine removes them.
+func (c *ClientS3) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for obj := range c.mc.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		keys = append(keys, obj.Key)
+	}
+	return keys, nil
+}
@@ -1,8 +1,59 @@
 package cmd
 
+import (
+	"time"
+
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/content"
+	"github.com/till/golangoss-bluesky/internal/moderation"
+	"github.com/till/golangoss-bluesky/internal/policy"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/spam"
+)
+
 type Config struct {
 	Handle      string
 	AppKey      string
 	CacheBucket string
 	GitHubToken string
+	GitHubApp   provider.AppCredentials
+	GiteaURL    string
+	GiteaToken  string
+	GitLabURL   string
+	GitLabToken string
+	CuratedList string
+	CuratedFile string
+	License     config.LicensePolicy
+	Quality     config.Quality
+	Policy      policy.Policy
+	Hashtags    config.Hashtags
+	Spam        spam.Classifier
+	Weights     config.Weights
+	PickTop     bool
+	TrendWindow time.Duration
+	Rates       *provider.RateTracker
+
+	// ReleaseRepos and ReleaseFeatured select the repos whose releases are
+	// announced, every ReleaseInterval. Release mode is off when neither is set.
+	ReleaseRepos    []string
+	ReleaseFeatured bool
+	ReleaseInterval time.Duration
+
+	// Moderation queues posts for approval on the admin page instead of
+	// posting them, nil posts right away. It's the queue the admin page
+	// decides on.
+	Moderation *moderation.Queue
+
+	// Campaigns to interleave over the session, the default Go campaign
+	// when empty.
+	Campaigns []content.Campaign
+
+	// GitHubResponseCache is where conditional-request responses are kept:
+	// "memory", "s3" or "off".
+	GitHubResponseCache string
+}
+
+// releasesEnabled reports whether the release watcher should run.
+func (c Config) releasesEnabled() bool {
+	return len(c.ReleaseRepos) > 0 || c.ReleaseFeatured
 }
@@ -13,12 +13,17 @@ import (
 	"github.com/till/golangoss-bluesky/internal/bluesky"
 	"github.com/till/golangoss-bluesky/internal/cache"
 	"github.com/till/golangoss-bluesky/internal/content"
+	"github.com/till/golangoss-bluesky/internal/optout"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/submission"
+	"github.com/till/golangoss-bluesky/internal/utils"
 )
 
 const (
-	checkInterval time.Duration = 15 * time.Minute
 	// How long to wait before retrying after a connection failure
 	reconnectDelay time.Duration = 2 * time.Minute
+	// How often to look for new releases when no interval is configured
+	defaultReleaseInterval time.Duration = time.Hour
 )
 
 // connectBluesky establishes a connection to Bluesky and logs in
@@ -46,12 +51,44 @@ func connectBluesky(ctx context.Context, handle, appKey string) (*bk.Client, err
 // RunWithReconnect attempts to run the bot with automatic reconnection on failure
 func RunWithReconnect(ctx context.Context, mc *minio.Client, cfg Config) error {
 	cacheClient := cache.NewClientS3(mc, cfg.CacheBucket)
+	optOuts := optout.NewStore(&cacheClient)
+	submissions := submission.NewQueue(&cacheClient)
 
 	cleanup := content.NewS3Cleanup(mc, cfg.CacheBucket)
 	cleanup.Start(ctx)
 	defer cleanup.Stop()
 
-	if err := content.Start(cfg.GitHubToken, cacheClient); err != nil {
+	if err := content.Start(content.Options{
+		Cache: &cacheClient,
+		GitHub: provider.GitHubOptions{
+			Token:         cfg.GitHubToken,
+			App:           cfg.GitHubApp,
+			ResponseCache: cfg.GitHubResponseCache,
+		},
+		License:     cfg.License,
+		Quality:     cfg.Quality,
+		Policy:      cfg.Policy,
+		Hashtags:    cfg.Hashtags,
+		Spam:        cfg.Spam,
+		Weights:     cfg.Weights,
+		PickTop:     cfg.PickTop,
+		TrendWindow: cfg.TrendWindow,
+		OptOuts:     optOuts,
+		Rates:       cfg.Rates,
+		Submissions: submissions,
+		GiteaURL:    cfg.GiteaURL,
+		GiteaToken:  cfg.GiteaToken,
+		GitLabURL:   cfg.GitLabURL,
+		GitLabToken: cfg.GitLabToken,
+		CuratedList: cfg.CuratedList,
+		CuratedFile: cfg.CuratedFile,
+
+		ReleaseRepos:    cfg.ReleaseRepos,
+		ReleaseFeatured: cfg.ReleaseFeatured,
+
+		Campaigns:  cfg.Campaigns,
+		Moderation: cfg.Moderation,
+	}); err != nil {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 
@@ -74,7 +111,13 @@ func RunWithReconnect(ctx context.Context, mc *minio.Client, cfg Config) error {
 		}
 
 		c := bluesky.Client{Client: client}
-		runSession(ctx, c)
+
+		sessionCtx, cancel := context.WithCancel(ctx)
+		if cfg.releasesEnabled() {
+			go runReleases(sessionCtx, c, cfg.ReleaseInterval)
+		}
+		runSession(sessionCtx, c)
+		cancel()
 		client.Close()
 
 		if err := sleepCtx(ctx, reconnectDelay); err != nil {
@@ -84,10 +127,15 @@ func RunWithReconnect(ctx context.Context, mc *minio.Client, cfg Config) error {
 }
 
 // runSession runs the inner check loop until ctx is cancelled or content.Do
-// returns a non-recoverable error. The caller is responsible for closing the
+// returns a non-recoverable error. Each round posts for the campaign that's
+// due, then sleeps until the next one is. The caller is responsible for closing the
 // bluesky client and reconnecting.
 func runSession(ctx context.Context, c bluesky.Client) {
 	for {
+		if err := content.ProcessOptOuts(ctx, c); err != nil {
+			utils.LogErrorWithContext(ctx, err)
+		}
+
 		slog.DebugContext(ctx, "checking...")
 		if err := content.Do(ctx, c); err != nil {
 			if !errors.Is(err, content.ErrCouldNotContent) {
@@ -96,7 +144,24 @@ func runSession(ctx context.Context, c bluesky.Client) {
 			}
 			slog.DebugContext(ctx, "backing off...")
 		}
-		if err := sleepCtx(ctx, checkInterval); err != nil {
+		if err := sleepCtx(ctx, time.Until(content.NextRun())); err != nil {
+			return
+		}
+	}
+}
+
+// runReleases checks the watched repos for new releases every interval
+// until ctx is cancelled. Errors are logged, the next check retries.
+func runReleases(ctx context.Context, c bluesky.Client, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultReleaseInterval
+	}
+	for {
+		slog.DebugContext(ctx, "checking releases...")
+		if err := content.DoReleases(ctx, c); err != nil {
+			utils.LogErrorWithContext(ctx, fmt.Errorf("release check: %w", err))
+		}
+		if err := sleepCtx(ctx, interval); err != nil {
 			return
 		}
 	}
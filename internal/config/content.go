@@ -1,12 +1,178 @@
 // Package config holds the tunables the content pipeline reads when it
 // asks the provider for the next repo to publish: which language to search,
-// whether to include archived repos, and how recent the last push must be.
+// whether to include archived repos, how recent the last push must be and
+// how candidates are ranked.
 package config
 
-import "time"
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/till/golangoss-bluesky/internal/policy"
+	"github.com/till/golangoss-bluesky/internal/spam"
+)
 
 type Config struct {
 	Language    string    // programming language
+	Topic       string    // only repos tagged with this topic, empty for any
 	Archived    bool      // if "false", it will filter out archived repos
 	PushedSince time.Time // filter to only get active repos
+
+	Weights Weights // how candidates are scored
+	PickTop bool    // pick the best candidate instead of sampling by score
+
+	// TrendWindow is the period star growth is measured over. Star counts
+	// are snapshotted daily while it's set; 0 disables trend tracking.
+	TrendWindow time.Duration
+
+	License  LicensePolicy
+	Quality  Quality
+	Policy   policy.Policy // hand-maintained deny, allow and boost rules
+	Hashtags Hashtags      // how repo topics become hashtags
+	Spam     spam.Classifier
+}
+
+// Quality filters out repos that aren't worth featuring. Zero values
+// disable the respective filter.
+type Quality struct {
+	MinStars           int
+	MaxStars           int // cap to skip projects everybody knows already
+	MinSizeKB          int // GitHub reports 0 for empty repos
+	ExcludeForks       bool
+	ExcludeTemplates   bool
+	ExcludeMirrors     bool
+	RequireDescription bool // the README's first paragraph counts as one
+	RequireGoModule    bool // skip GitHub repos without a go.mod at the root
+}
+
+// OSILicenses are the SPDX IDs of the common OSI-approved licenses. Used as
+// the default allowlist.
+var OSILicenses = []string{
+	"MIT",
+	"Apache-2.0",
+	"BSD-2-Clause",
+	"BSD-3-Clause",
+	"0BSD",
+	"ISC",
+	"MPL-2.0",
+	"LGPL-2.1",
+	"LGPL-3.0",
+	"GPL-2.0",
+	"GPL-3.0",
+	"AGPL-3.0",
+	"EPL-2.0",
+	"Unlicense",
+}
+
+// LicensePolicy decides which licenses a repo may have. Every source
+// enforces it; on forges that don't detect licenses, such as Forgejo, the
+// repo's license file is read instead.
+type LicensePolicy struct {
+	Required   bool     // reject repos without a recognized license
+	Allowed    []string // SPDX IDs; empty allows any license
+	ShowInPost bool     // add the SPDX ID to the post text
+}
+
+// Allows reports whether a repo with the given SPDX ID passes the policy.
+// An empty ID or "NOASSERTION" means GitHub didn't recognize a license.
+func (l LicensePolicy) Allows(spdxID string) bool {
+	if spdxID == "" || spdxID == "NOASSERTION" {
+		return !l.Required && len(l.Allowed) == 0
+	}
+	if len(l.Allowed) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(l.Allowed, func(id string) bool {
+		return strings.EqualFold(id, spdxID)
+	})
+}
+
+// Weights scale the signals a candidate is scored on. Each signal is
+// normalized to roughly [0, 1] (stars, forks and issues on a log scale), so
+// the weights are directly comparable. A zero weight disables the signal.
+type Weights struct {
+	Stars       float64
+	Forks       float64
+	Description float64 // length of the description
+	License     float64 // has a license
+	Topics      float64 // number of topics
+	RecentPush  float64 // how recently it was pushed to
+	Issues      float64 // open issue count, as a proxy for activity
+	StarGrowth  float64 // stars gained over Config.TrendWindow
+}
+
+// DefaultWeights favors popular, documented and licensed repos.
+func DefaultWeights() Weights {
+	return Weights{
+		Stars:       1,
+		Forks:       0.5,
+		Description: 1,
+		License:     1,
+		Topics:      0.5,
+		RecentPush:  1,
+		Issues:      0.25,
+		StarGrowth:  1,
+	}
+}
+
+// weightFields maps the signal names, as logged in a score's breakdown, to
+// their weight.
+func (w *Weights) weightFields() map[string]*float64 {
+	return map[string]*float64{
+		"stars":       &w.Stars,
+		"forks":       &w.Forks,
+		"description": &w.Description,
+		"license":     &w.License,
+		"topics":      &w.Topics,
+		"recent_push": &w.RecentPush,
+		"issues":      &w.Issues,
+		"star_growth": &w.StarGrowth,
+	}
+}
+
+// ParseWeights applies signal=weight pairs, e.g. "stars=2" or "issues=0",
+// to the default weights.
+func ParseWeights(pairs []string) (Weights, error) {
+	w := DefaultWeights()
+	fields := w.weightFields()
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		field, known := fields[strings.ToLower(strings.TrimSpace(name))]
+		if !ok || !known {
+			return Weights{}, fmt.Errorf("invalid score weight %q, expected signal=weight with a signal of %s", pair, strings.Join(slices.Sorted(maps.Keys(fields)), ", "))
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || v < 0 {
+			return Weights{}, fmt.Errorf("invalid score weight %q, expected a number of at least 0", pair)
+		}
+		*field = v
+	}
+	return w, nil
+}
+
+// Hashtags controls which repo topics are added to the post as hashtags,
+// after the lead tag.
+type Hashtags struct {
+	// Map renames topics, e.g. "golang" to "go". A topic mapped to ""
+	// is dropped. Unmapped topics are used as they are.
+	Map       map[string]string
+	Lead      string // first tag, defaults to the language
+	Max       int    // max number of hashtags, including the lead tag
+	MaxLength int    // topics longer than this are dropped, 0 means no limit
+}
+
+// DefaultHashtagMap folds the usual spellings of the language into one tag
+// and drops topics that say nothing on a Go bot.
+func DefaultHashtagMap() map[string]string {
+	return map[string]string{
+		"golang":        "go",
+		"go-lang":       "go",
+		"go-library":    "",
+		"go-module":     "",
+		"hacktoberfest": "",
+	}
 }
@@ -0,0 +1,54 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+)
+
+func TestLicensePolicy_Allows(t *testing.T) {
+	osi := config.LicensePolicy{Required: true, Allowed: config.OSILicenses}
+
+	testCases := []struct {
+		name   string
+		policy config.LicensePolicy
+		spdx   string
+		want   bool
+	}{
+		{"no policy, no license", config.LicensePolicy{}, "", true},
+		{"no policy, any license", config.LicensePolicy{}, "BUSL-1.1", true},
+		{"required, no license", config.LicensePolicy{Required: true}, "", false},
+		{"required, unrecognized", config.LicensePolicy{Required: true}, "NOASSERTION", false},
+		{"required, any license", config.LicensePolicy{Required: true}, "BUSL-1.1", true},
+		{"allowlist, listed", osi, "Apache-2.0", true},
+		{"allowlist, case-insensitive", osi, "mit", true},
+		{"allowlist, not listed", osi, "BUSL-1.1", false},
+		{"allowlist implies required", config.LicensePolicy{Allowed: []string{"MIT"}}, "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.policy.Allows(tc.spdx))
+		})
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	w, err := config.ParseWeights([]string{"stars=2", " Issues = 0 ", "star_growth=1.5"})
+	require.NoError(t, err)
+
+	want := config.DefaultWeights()
+	want.Stars, want.Issues, want.StarGrowth = 2, 0, 1.5
+	assert.Equal(t, want, w)
+
+	none, err := config.ParseWeights(nil)
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultWeights(), none)
+
+	for _, invalid := range []string{"stars", "popularity=1", "stars=many", "stars=-1"} {
+		_, err := config.ParseWeights([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,148 @@
+package content
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	ghprovider "github.com/till/golangoss-bluesky/internal/provider"
+)
+
+// defaultInterval is the time between posts of a campaign that doesn't set
+// its own.
+const defaultInterval = 15 * time.Minute
+
+// Campaign is a posting schedule of its own: which repos to look for, how to
+// tag them and how often to post. Each campaign keeps its seen repos and
+// search state in its own cache namespace, so sister campaigns may feature
+// the same repo.
+type Campaign struct {
+	// Name is the cache namespace and must not contain ":". The default
+	// campaign has none and uses the keys the bot always used.
+	Name     string
+	Language string
+	// Topic restricts the campaign to repos tagged with it, e.g.
+	// "webassembly" for a Go + Wasm campaign.
+	Topic string
+	// Hashtag leads the post's hashtags, defaults to the language.
+	Hashtag  string
+	Interval time.Duration // time between posts, defaults to defaultInterval
+}
+
+// DefaultCampaign posts Go repos every defaultInterval.
+func DefaultCampaign() Campaign {
+	return Campaign{Language: "go", Interval: defaultInterval}
+}
+
+// UnmarshalJSON reads the interval as a duration string like "30m".
+func (c *Campaign) UnmarshalJSON(data []byte) error {
+	type plain Campaign
+	var raw struct {
+		plain
+		Interval string `json:"interval"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*c = Campaign(raw.plain)
+	if raw.Interval == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(raw.Interval)
+	if err != nil {
+		return fmt.Errorf("campaign %q: interval: %w", c.Name, err)
+	}
+	c.Interval = d
+	return nil
+}
+
+// LoadCampaigns reads a JSON list of campaigns, e.g.
+//
+//	[{"language": "go"}, {"name": "templ", "language": "templ", "interval": "1h"}]
+//
+// An empty file name returns no campaigns, Start then runs the default one.
+func LoadCampaigns(file string) ([]Campaign, error) {
+	if file == "" {
+		return nil, nil
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read campaigns: %w", err)
+	}
+	var out []Campaign
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("parse campaigns %s: %w", file, err)
+	}
+	return out, nil
+}
+
+// campaign is a started Campaign: its sources, its GitHub provider and
+// cache namespace, and when it's due next.
+type campaign struct {
+	Campaign
+	// submissions is asked ahead of the weighted sources, nil when the
+	// campaign doesn't post submissions.
+	submissions ghprovider.Source
+	sources     []weightedSource
+	github      ghprovider.Provider
+	cache       ghprovider.Cache
+	next        time.Time
+}
+
+// candidates returns the campaign's sources in the order they're asked:
+// submissions first, then the weighted ones in the order intN draws.
+func (c *campaign) candidates(intN func(int) int) []ghprovider.Source {
+	out := order(c.sources, intN)
+	if c.submissions != nil {
+		out = append([]ghprovider.Source{c.submissions}, out...)
+	}
+	return out
+}
+
+// schedule sets the campaign's next post one interval after now.
+func (c *campaign) schedule(now time.Time) {
+	c.next = now.Add(c.Interval)
+}
+
+// due returns the campaign whose next post is the earliest, ties going to
+// the one listed first.
+func due(cs []*campaign) *campaign {
+	var out *campaign
+	for _, c := range cs {
+		if out == nil || c.next.Before(out.next) {
+			out = c
+		}
+	}
+	return out
+}
+
+// namespacePrefix starts the keys of every named campaign. None of the keys
+// the default campaign writes starts with it, and as names can't contain ":"
+// no campaign's prefix starts another's.
+const namespacePrefix = "campaign:"
+
+// namespaced prefixes every key with a campaign's name.
+type namespaced struct {
+	cache  ghprovider.Cache
+	prefix string
+}
+
+// namespace returns cc with keys prefixed by "campaign:<name>:", or cc itself
+// when name is empty.
+func namespace(cc ghprovider.Cache, name string) ghprovider.Cache {
+	if name == "" {
+		return cc
+	}
+	return namespaced{cache: cc, prefix: namespacePrefix + name + ":"}
+}
+
+func (n namespaced) Get(ctx context.Context, key string) (string, error) {
+	return n.cache.Get(ctx, n.prefix+key)
+}
+
+func (n namespaced) Set(ctx context.Context, key string, value any, exp time.Duration) error {
+	return n.cache.Set(ctx, n.prefix+key, value, exp)
+}
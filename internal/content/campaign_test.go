@@ -0,0 +1,159 @@
+package content_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/content"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestLoadCampaigns(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "campaigns.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[
+		{"language": "go"},
+		{"name": "templ", "language": "templ", "hashtag": "#templ", "interval": "1h"},
+		{"name": "wasm", "language": "go", "topic": "webassembly", "interval": "45m"}
+	]`), 0o600))
+
+	camps, err := content.LoadCampaigns(file)
+	require.NoError(t, err)
+	assert.Equal(t, []content.Campaign{
+		{Language: "go"},
+		{Name: "templ", Language: "templ", Hashtag: "#templ", Interval: time.Hour},
+		{Name: "wasm", Language: "go", Topic: "webassembly", Interval: 45 * time.Minute},
+	}, camps)
+
+	none, err := content.LoadCampaigns("")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+func TestLoadCampaigns_InvalidInterval(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "campaigns.json")
+	require.NoError(t, os.WriteFile(file, []byte(`[{"name": "templ", "interval": "hourly"}]`), 0o600))
+
+	_, err := content.LoadCampaigns(file)
+	require.ErrorContains(t, err, `campaign "templ"`)
+}
+
+func TestDue(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		intervals map[string]time.Duration
+		order     []string // campaigns as listed
+		want      []string // campaigns in the order they post
+	}{
+		{
+			name:      "same interval takes turns",
+			intervals: map[string]time.Duration{"a": 30 * time.Minute, "b": 30 * time.Minute, "c": 30 * time.Minute},
+			order:     []string{"a", "b", "c"},
+			want:      []string{"a", "b", "c", "a", "b", "c"},
+		},
+		{
+			name:      "short interval posts more often",
+			intervals: map[string]time.Duration{"a": 15 * time.Minute, "b": time.Hour},
+			order:     []string{"a", "b"},
+			// b is due again at 13:00 along with a, which is listed first
+			want: []string{"a", "b", "a", "a", "a", "a", "b", "a"},
+		},
+		{
+			name:      "uneven intervals",
+			intervals: map[string]time.Duration{"a": time.Hour, "b": 20 * time.Minute, "c": 45 * time.Minute},
+			order:     []string{"a", "b", "c"},
+			// a 13:00, b every 20m, c 12:45 and 13:30
+			want: []string{"a", "b", "c", "b", "b", "c", "a", "b", "b", "c"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var cs []*content.Scheduled
+			for _, name := range tc.order {
+				cs = append(cs, content.NewScheduled(content.Campaign{Name: name, Interval: tc.intervals[name]}))
+			}
+
+			// like the bot: wait until the next one is due, post, reschedule
+			now := start
+			var got []string
+			for range tc.want {
+				c := content.Due(cs)
+				if c.NextAt().After(now) {
+					now = c.NextAt()
+				}
+				got = append(got, c.Name)
+				c.Schedule(now)
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	assert.Nil(t, content.Due(nil))
+}
+
+func TestNamespace(t *testing.T) {
+	ctx := context.Background()
+	cache := testutil.MemCache{}
+
+	// "repo" and "stars" are also kinds of key the default campaign writes,
+	// "a" and "ab" share their first letter.
+	names := []string{"", "repo", "stars", "a", "ab", "templ"}
+	keys := []string{"repo:1", "cursor:github-search", "cursor:gitea:codeberg.org", "stars:2026-10-01", "a:repo:1"}
+
+	for _, name := range names {
+		ns := content.Namespace(cache, name)
+		for _, key := range keys {
+			require.NoError(t, ns.Set(ctx, key, name, 0))
+		}
+	}
+	assert.Len(t, cache, len(names)*len(keys), "no two campaigns wrote the same key")
+
+	for _, name := range names {
+		ns := content.Namespace(cache, name)
+		for _, key := range keys {
+			got, err := ns.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, name, got, "campaign %q, key %q", name, key)
+		}
+	}
+
+	assert.Contains(t, cache, "repo:1", "the default campaign keeps its keys")
+	assert.Contains(t, cache, "campaign:templ:repo:1")
+}
+
+func TestStart_CampaignNameWithColon(t *testing.T) {
+	err := content.Start(content.Options{Campaigns: []content.Campaign{{Name: "go:wasm", Language: "go"}}})
+	require.ErrorContains(t, err, `campaign "go:wasm"`)
+}
+
+func TestCandidates_SubmissionsFirst(t *testing.T) {
+	ctx := context.Background()
+	subs := &fakeSource{name: "submissions", item: &provider.Content{Key: "repo:10"}}
+	gitea := &fakeSource{name: "gitea", item: &provider.Content{Key: "gitea:codeberg.org:1"}}
+	github := &fakeSource{name: "github", item: &provider.Content{Key: "repo:1"}}
+
+	// the draw picks gitea despite github's weight
+	first := func(int) int { return 0 }
+	camp := content.NewRotation(subs, content.NewWeighted(gitea, 1), content.NewWeighted(github, 100))
+	assert.Equal(t, []provider.Source{subs, gitea, github}, camp.Candidates(first))
+
+	got, err := content.Next(ctx, camp.Candidates(first))
+	require.NoError(t, err)
+	assert.Equal(t, "repo:10", got.Key, "the submission goes ahead of gitea")
+	assert.False(t, gitea.seen)
+
+	// once the queue is drained the rotation's pick posts
+	got, err = content.Next(ctx, camp.Candidates(first))
+	require.NoError(t, err)
+	assert.Equal(t, "gitea:codeberg.org:1", got.Key)
+
+	without := content.NewRotation(nil, content.NewWeighted(gitea, 1), content.NewWeighted(github, 100))
+	assert.Equal(t, []provider.Source{gitea, github}, without.Candidates(first))
+}
@@ -5,17 +5,41 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/till/golangoss-bluesky/internal/bluesky"
 	"github.com/till/golangoss-bluesky/internal/cache"
 	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/moderation"
+	"github.com/till/golangoss-bluesky/internal/optout"
+	"github.com/till/golangoss-bluesky/internal/policy"
 	ghprovider "github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/spam"
 	"github.com/till/golangoss-bluesky/internal/utils"
 )
 
 var (
-	prov *ghprovider.Provider
+	campaigns []*campaign
+
+	// gh is the GitHub provider of the first campaign, also used to verify
+	// opt-out requests and to watch releases.
+	gh ghprovider.Provider
+	// optOuts is the registry of maintainers who don't want to be featured.
+	optOuts optout.Store
+
+	// watchRepos and watchFeatured select the repos DoReleases checks.
+	watchRepos    []string
+	watchFeatured bool
+
+	// showLicense adds the SPDX ID next to the star count.
+	showLicense bool
+
+	// moderated holds posts for approval instead of posting them, nil
+	// posts right away.
+	moderated *moderation.Queue
 
 	// ErrCouldNotContent is returned when content cannot be fetched
 	ErrCouldNotContent = errors.New("could not get content")
@@ -25,47 +49,575 @@ var (
 // alive. Anything older is filtered out at the search layer.
 const activeWithin = 365 * 24 * time.Hour
 
-// Start bootstraps the content provider
-func Start(token string, cacheClient cache.ClientS3) error {
+// releasesPerCheck caps the announcements per release check, so a busy day
+// doesn't flood the feed. The rest is posted by the next check.
+const releasesPerCheck = 3
+
+// approvedPerCycle caps the approved posts published per cycle, the rest
+// wait for the next one.
+const approvedPerCycle = 3
+
+// Weights of the built-in sources in the rotation.
+const (
+	githubWeight  = 3
+	giteaWeight   = 1
+	gitlabWeight  = 1
+	curatedWeight = 1
+)
+
+// Options configures the sources Start registers.
+type Options struct {
+	Cache  ghprovider.Cache
+	GitHub ghprovider.GitHubOptions
+
+	// License restricts candidates to repos with an allowed license.
+	License config.LicensePolicy
+	// Quality filters out forks, templates, empty repos and the like.
+	Quality config.Quality
+	// Policy holds the deny, allow and boost rules from the policy file.
+	Policy policy.Policy
+	// Hashtags turns repo topics into hashtags.
+	Hashtags config.Hashtags
+	// Spam rejects scams, spam and homework dumps.
+	Spam spam.Classifier
+	// Weights rank the GitHub search results, the zero value picks at
+	// random. PickTop picks the best one instead of sampling by score.
+	Weights config.Weights
+	PickTop bool
+	// TrendWindow is the period star growth is measured over, 0 disables
+	// trend tracking.
+	TrendWindow time.Duration
+	// OptOuts lists maintainers who asked not to be featured.
+	OptOuts optout.Store
+	// Rates collects GitHub's rate limits for the stats page.
+	Rates *ghprovider.RateTracker
+	// Submissions are repos suggested via the stats server. The first
+	// campaign posts them ahead of every other source.
+	Submissions ghprovider.SubmissionQueue
+
+	// GiteaURL enables the Gitea/Forgejo source when set, e.g. https://codeberg.org.
+	GiteaURL   string
+	GiteaToken string
+
+	// GitLabURL enables the GitLab source when set, e.g. https://gitlab.com.
+	GitLabURL   string
+	GitLabToken string
+
+	// CuratedList enables the curated source: the GitHub repo ("owner/name")
+	// of an awesome list, e.g. avelino/awesome-go. CuratedFile is a local
+	// copy of the list read instead when set.
+	CuratedList string
+	CuratedFile string
+
+	// ReleaseRepos are GitHub repos ("owner/name") whose releases DoReleases
+	// announces. ReleaseFeatured adds every repo we've posted about.
+	ReleaseRepos    []string
+	ReleaseFeatured bool
+
+	// Campaigns to run, DefaultCampaign when empty.
+	Campaigns []Campaign
+
+	// Moderation holds the repo posts until a moderator approves them;
+	// nil posts right away. Release announcements aren't moderated.
+	Moderation *moderation.Queue
+}
+
+// weightedSource is a registered source and its share of the rotation.
+type weightedSource struct {
+	src    ghprovider.Source
+	weight int
+}
+
+// Start bootstraps the GitHub search provider, plus any other source enabled
+// in opts, for each campaign and registers them.
+func Start(opts Options) error {
+	showLicense = opts.License.ShowInPost
+	watchRepos, watchFeatured = opts.ReleaseRepos, opts.ReleaseFeatured
+	optOuts = opts.OptOuts
+	moderated = opts.Moderation
+
+	camps := opts.Campaigns
+	if len(camps) == 0 {
+		camps = []Campaign{DefaultCampaign()}
+	}
+
+	names := map[string]bool{}
+	for i, camp := range camps {
+		if names[camp.Name] {
+			return fmt.Errorf("campaign %q: names must be unique", camp.Name)
+		}
+		if strings.Contains(camp.Name, ":") {
+			return fmt.Errorf("campaign %q: names can't contain \":\"", camp.Name)
+		}
+		names[camp.Name] = true
+
+		campOpts := opts
+		if i > 0 {
+			campOpts.Submissions = nil
+		}
+		p, err := startCampaign(campOpts, camp)
+		if err != nil {
+			return fmt.Errorf("campaign %q: %w", camp.Name, err)
+		}
+		if i == 0 {
+			gh = p
+		}
+	}
+	return nil
+}
+
+// startCampaign sets up the sources of one campaign and returns its GitHub
+// provider.
+func startCampaign(opts Options, camp Campaign) (ghprovider.Provider, error) {
+	if camp.Interval <= 0 {
+		camp.Interval = defaultInterval
+	}
+	hashtags := opts.Hashtags
+	hashtags.Lead = camp.Hashtag
+
 	cfg := config.Config{
-		Language:    "go",
+		Language:    camp.Language,
+		Topic:       camp.Topic,
 		Archived:    false,
 		PushedSince: time.Now().UTC().Add(-activeWithin),
+		Weights:     opts.Weights,
+		PickTop:     opts.PickTop,
+		TrendWindow: opts.TrendWindow,
+		License:     opts.License,
+		Quality:     opts.Quality,
+		Policy:      opts.Policy,
+		Hashtags:    hashtags,
+		Spam:        opts.Spam,
+	}
+	cc := namespace(opts.Cache, camp.Name)
+	started := &campaign{Campaign: camp, cache: cc}
+	campaigns = append(campaigns, started)
+
+	p, err := ghprovider.NewProvider(opts.GitHub, cfg, cc)
+	if err != nil {
+		return p, err
+	}
+	p.OptOuts = opts.OptOuts
+	p.Rates = opts.Rates
+	p.Submissions = opts.Submissions
+	started.github = p
+	if opts.Submissions != nil {
+		started.submissions = ghprovider.NewSubmissions(p)
+	}
+	if err := Register(camp.Name, p, githubWeight); err != nil {
+		return p, err
+	}
+
+	if opts.GiteaURL != "" {
+		g, err := ghprovider.NewGitea(opts.GiteaURL, opts.GiteaToken, cfg, cc)
+		if err != nil {
+			return p, err
+		}
+		if err := Register(camp.Name, g, giteaWeight); err != nil {
+			return p, err
+		}
+	}
+
+	if opts.GitLabURL != "" {
+		g, err := ghprovider.NewGitLab(opts.GitLabURL, opts.GitLabToken, cfg, cc)
+		if err != nil {
+			return p, err
+		}
+		if err := Register(camp.Name, g, gitlabWeight); err != nil {
+			return p, err
+		}
+	}
+
+	if opts.CuratedList != "" || opts.CuratedFile != "" {
+		cur, err := ghprovider.NewCurated(p, opts.CuratedList, opts.CuratedFile)
+		if err != nil {
+			return p, err
+		}
+		if err := Register(camp.Name, cur, curatedWeight); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// Register adds a source to the rotation of the named campaign. A source
+// with weight 3 is asked first three times as often as one with weight 1.
+// Weights below 1 are treated as 1. Not safe to call while Do is running.
+func Register(campaignName string, src ghprovider.Source, weight int) error {
+	for _, c := range campaigns {
+		if c.Name == campaignName {
+			c.sources = append(c.sources, weightedSource{src: src, weight: max(weight, 1)})
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown campaign %q", campaignName)
+}
+
+// NextRun returns when the next campaign is due to post.
+func NextRun() time.Time {
+	if c := due(campaigns); c != nil {
+		return c.next
+	}
+	return time.Now().Add(defaultInterval)
+}
+
+// Do posts for the campaign that's due next, whether or not its time has
+// come; callers wait for NextRun first. The campaign is then scheduled
+// again one interval later, also when it had nothing to post. With
+// moderation on, the campaign's post is queued for approval and the posts
+// approved since the last cycle are published instead.
+func Do(ctx context.Context, c bluesky.Client) error {
+	if moderated != nil {
+		if err := processModeration(ctx, c); err != nil {
+			utils.LogErrorWithContext(ctx, fmt.Errorf("moderation: %w", err))
+		}
+	}
+
+	camp := due(campaigns)
+	if camp == nil {
+		return nil
+	}
+	camp.schedule(time.Now())
+
+	slog.DebugContext(ctx, "running campaign", "campaign", camp.Name, "language", camp.Language)
+	return camp.do(ctx, c)
+}
+
+// do gets content from one of the campaign's sources and posts it to
+// bluesky. Queued submissions come first, then the sources are asked in
+// weighted random order until one returns a candidate.
+func (camp *campaign) do(ctx context.Context, c bluesky.Client) error {
+	item, err := next(ctx, camp.candidates(rand.IntN))
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		slog.DebugContext(ctx, "nothing found")
+		return nil
+	}
+
+	author, authorDID := credit(ctx, &c, item.Author)
+	stargazers := fmt.Sprintf("⭐️ %d", item.Stars)
+	if item.StarGrowth > 0 {
+		stargazers += fmt.Sprintf(", +%d ⭐️ %s", item.StarGrowth, growthPeriod(item.GrowthWindow))
+	}
+	if showLicense && item.License != "" {
+		stargazers += " · " + item.License
+	}
+
+	module := ""
+	if item.Module != nil {
+		module = item.Module.Path
+		stargazers += " · " + item.Module.Summary()
 	}
 
-	p, err := ghprovider.NewProvider(token, cfg, cacheClient)
+	post := bluesky.PostRecord(bluesky.PostInput{
+		Title:       item.Title,
+		Description: item.Description,
+		URL:         item.URL,
+		Author: bluesky.PostAuthor{
+			Name: author,
+			DID:  authorDID,
+			URL:  item.Author.ProfileURL,
+		},
+		Stars:    stargazers,
+		Module:   module,
+		Hashtags: item.Hashtag,
+	})
+	if moderated == nil {
+		return c.Post(ctx, post)
+	}
+
+	queued, err := moderated.Add(ctx, moderation.Item{
+		Campaign: camp.Name,
+		Key:      item.Key,
+		Repo:     item.FullName,
+		URL:      item.URL,
+		Post:     post,
+	})
 	if err != nil {
 		return err
 	}
-	prov = &p
+	slog.InfoContext(ctx, "post awaiting approval", "id", queued.ID, "repo", item.FullName)
 	return nil
 }
 
-// Do gets content from the provider and posts it to bluesky
-func Do(ctx context.Context, c bluesky.Client) error {
-	item, err := prov.GetContentToPublish(ctx)
+// resolver looks up Bluesky accounts, see bluesky.Client.
+type resolver interface {
+	ResolveHandle(ctx context.Context, handle string) (string, error)
+	VerifiedHandle(ctx context.Context, did string) (string, error)
+}
+
+// credit returns how the post names the author and the DID it mentions.
+// The maintainer is mentioned as "@handle" when their Bluesky account
+// checks out: a handle has to resolve, and a DID has to have a handle that
+// resolves back to it. Otherwise the post keeps the forge "@login", linked
+// to the forge profile, and there's no DID.
+func credit(ctx context.Context, r resolver, a ghprovider.Author) (author, did string) {
+	if a.Login != "" {
+		author = "@" + a.Login
+	}
+
+	switch {
+	case a.BlueskyHandle != "":
+		handle := strings.TrimPrefix(a.BlueskyHandle, "@")
+		resolved, err := r.ResolveHandle(ctx, handle)
+		if err != nil {
+			slog.WarnContext(ctx, "not mentioning author", "handle", handle, "err", err)
+			return author, ""
+		}
+		return "@" + handle, resolved
+	case a.BlueskyDID != "":
+		handle, err := r.VerifiedHandle(ctx, a.BlueskyDID)
+		if err != nil {
+			slog.WarnContext(ctx, "not mentioning author", "did", a.BlueskyDID, "err", err)
+			return author, ""
+		}
+		return "@" + handle, a.BlueskyDID
+	}
+	return author, ""
+}
+
+// processModeration acts on the moderators' decisions: approved posts are
+// published, at most approvedPerCycle of them. Skipped, blocked and
+// expired ones are taken off the featured list, and a blocked repo's key
+// is kept for good so it isn't picked again. Each item is claimed first,
+// so no decision lands on it while it's being posted.
+func processModeration(ctx context.Context, c bluesky.Client) error {
+	items, err := moderated.List(ctx)
 	if err != nil {
-		utils.LogError(fmt.Errorf("error fetching content: %w", err))
-		return ErrCouldNotContent
+		return err
 	}
 
-	if item == nil {
-		slog.Debug("nothing found")
+	published := 0
+	now := time.Now()
+	for _, listed := range items {
+		switch {
+		case listed.Status == moderation.StatusPending && !listed.Expired(now):
+			continue
+		case listed.Status == moderation.StatusApproved && published == approvedPerCycle:
+			continue
+		case listed.Status == moderation.StatusPosting:
+			// claimed by a cycle that didn't get to remove it, it may well
+			// have been posted
+			slog.WarnContext(ctx, "dropping moderation item left claimed", "id", listed.ID, "repo", listed.Repo)
+			if err := moderated.Remove(ctx, listed.ID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// the decision may have changed since the listing, act on the
+		// one it was claimed with
+		it, ok, err := moderated.Claim(ctx, listed.ID)
+		if errors.Is(err, moderation.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// still waiting for a decision
+			continue
+		}
+
+		if err := settle(ctx, c, it, now); err != nil {
+			if uerr := moderated.Unclaim(ctx, it); uerr != nil {
+				utils.LogErrorWithContext(ctx, fmt.Errorf("unclaim %s: %w", it.ID, uerr))
+			}
+			return err
+		}
+		if it.Status == moderation.StatusApproved {
+			published++
+		}
+		if err := moderated.Remove(ctx, it.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settle acts on the decision of a claimed item.
+func settle(ctx context.Context, c bluesky.Client, it moderation.Item, now time.Time) error {
+	if it.Status == moderation.StatusApproved {
+		it.Post.CreatedAt = now.Format(time.RFC3339)
+		if err := c.Post(ctx, it.Post); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "posted approved item", "id", it.ID, "repo", it.Repo)
 		return nil
 	}
 
-	author := ""
-	if item.Author.GitHubLogin != "" {
-		author = "@" + item.Author.GitHubLogin
+	if err := turnDown(ctx, it); err != nil {
+		return err
 	}
-	stargazers := fmt.Sprintf("⭐️ %d", item.Stars)
+	slog.InfoContext(ctx, "dropped moderation item", "id", it.ID, "repo", it.Repo, "status", it.Status, "expired", it.Expired(now))
+	return nil
+}
+
+// turnDown cleans up after an item that won't be posted.
+func turnDown(ctx context.Context, it moderation.Item) error {
+	idx := slices.IndexFunc(campaigns, func(c *campaign) bool { return c.Name == it.Campaign })
+	if idx < 0 {
+		// the campaign is gone since the item was queued
+		return nil
+	}
+	camp := campaigns[idx]
+
+	if err := camp.github.ForgetFeatured(ctx, it.Repo); err != nil {
+		return err
+	}
+	if it.Status == moderation.StatusBlocked && it.Key != "" {
+		return camp.cache.Set(ctx, it.Key, "blocked by moderator", cache.NoExpiry)
+	}
+	return nil
+}
+
+// DoReleases announces new releases of the watched repos. It posts at most
+// releasesPerCheck of them; the others stay unseen for the next check.
+func DoReleases(ctx context.Context, c bluesky.Client) error {
+	repos := slices.Clone(watchRepos)
+	if watchFeatured {
+		featured, err := gh.FeaturedRepos(ctx)
+		if err != nil {
+			return err
+		}
+		for _, r := range featured {
+			if !slices.ContainsFunc(repos, func(w string) bool { return strings.EqualFold(w, r) }) {
+				repos = append(repos, r)
+			}
+		}
+	}
+	if len(repos) == 0 {
+		return nil
+	}
+
+	// a failed lookup still leaves the releases found before it
+	releases, checkErr := gh.NewReleases(ctx, repos)
+	for i, r := range releases {
+		if i == releasesPerCheck {
+			break
+		}
+		if err := gh.MarkReleaseSeen(ctx, r); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "announcing release", "repo", r.Repo, "tag", r.Tag)
+		if err := c.Post(ctx, bluesky.ReleaseRecord(
+			r.Repo,
+			r.Tag,
+			r.Name,
+			r.Notes,
+			r.URL,
+			r.Hashtag,
+		)); err != nil {
+			return err
+		}
+	}
+	return checkErr
+}
+
+// ProcessOptOuts registers the maintainers who replied "opt out" to one of
+// our posts since the last run. A request only counts when it comes from
+// the Bluesky account the credited GitHub user links on their profile. The
+// bot's notifications are left unread.
+func ProcessOptOuts(ctx context.Context, c bluesky.Client) error {
+	since, err := optOuts.RepliesSeen(ctx)
+	if err != nil {
+		return fmt.Errorf("load replies cursor: %w", err)
+	}
+	replies, newest, err := c.OptOutReplies(ctx, since)
+	if err != nil {
+		return fmt.Errorf("fetch replies: %w", err)
+	}
+
+	for _, r := range replies {
+		ok, err := gh.HasBlueskyAccount(ctx, r.GitHubLogin, r.Handle, r.DID)
+		if err != nil {
+			utils.LogErrorWithContext(ctx, fmt.Errorf("verify opt-out of %s: %w", r.GitHubLogin, err))
+			continue
+		}
+		if !ok {
+			slog.InfoContext(ctx, "ignoring opt-out from unlinked account", "login", r.GitHubLogin, "handle", r.Handle)
+			continue
+		}
+		if err := optOuts.Add(ctx, r.GitHubLogin, "reply "+r.URI); err != nil {
+			return fmt.Errorf("opt out %s: %w", r.GitHubLogin, err)
+		}
+		slog.InfoContext(ctx, "maintainer opted out", "login", r.GitHubLogin, "handle", r.Handle)
+	}
+
+	if newest.After(since) {
+		if err := optOuts.SetRepliesSeen(ctx, newest); err != nil {
+			return fmt.Errorf("save replies cursor: %w", err)
+		}
+	}
+	return nil
+}
+
+// next asks the sources in turn until one returns a candidate. A failing
+// source is logged and the next one asked; ErrCouldNotContent is returned
+// when none had a candidate and at least one failed.
+func next(ctx context.Context, sources []ghprovider.Source) (*ghprovider.Content, error) {
+	failed := false
+	for _, src := range sources {
+		item, err := ghprovider.GetContentToPublish(ctx, src)
+		if err != nil {
+			utils.LogErrorWithContext(ctx, fmt.Errorf("error fetching content: %w", err))
+			failed = true
+			continue
+		}
+		if item != nil {
+			return item, nil
+		}
+	}
+	if failed {
+		return nil, ErrCouldNotContent
+	}
+	return nil, nil
+}
+
+// growthPeriod phrases the trend window for the post, in hours when it's
+// shorter than a day.
+func growthPeriod(d time.Duration) string {
+	if d < 24*time.Hour {
+		if hours := int(d.Hours()); hours > 1 {
+			return fmt.Sprintf("in %d hours", hours)
+		}
+		return "in the last hour"
+	}
+
+	switch days := int(d.Hours() / 24); days {
+	case 1:
+		return "today"
+	case 7:
+		return "this week"
+	default:
+		return fmt.Sprintf("in %d days", days)
+	}
+}
 
-	return c.Post(ctx, bluesky.PostRecord(
-		item.Title,
-		item.Description,
-		item.URL,
-		author,
-		stargazers,
-		item.Hashtag,
-	))
+// order returns the sources in weighted random order: each position is drawn
+// proportionally to weight from the sources not yet placed. intN is
+// rand.IntN outside of tests.
+func order(ws []weightedSource, intN func(int) int) []ghprovider.Source {
+	left := make([]weightedSource, len(ws))
+	copy(left, ws)
+
+	out := make([]ghprovider.Source, 0, len(ws))
+	for len(left) > 0 {
+		total := 0
+		for _, w := range left {
+			total += w.weight
+		}
+		n := intN(total)
+		for i, w := range left {
+			if n < w.weight {
+				out = append(out, w.src)
+				left = append(left[:i], left[i+1:]...)
+				break
+			}
+			n -= w.weight
+		}
+	}
+	return out
 }
@@ -0,0 +1,205 @@
+package content_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/content"
+	"github.com/till/golangoss-bluesky/internal/provider"
+)
+
+// fakeSource hands out item once, or fails with err.
+type fakeSource struct {
+	name string
+	item *provider.Content
+	err  error
+	seen bool
+}
+
+func (f *fakeSource) Next(context.Context) (*provider.Content, error) {
+	if f.err != nil || f.seen {
+		return nil, f.err
+	}
+	return f.item, nil
+}
+
+func (f *fakeSource) Enrich(context.Context, *provider.Content) error { return nil }
+
+func (f *fakeSource) MarkSeen(context.Context, *provider.Content) error {
+	f.seen = true
+	return nil
+}
+
+func TestOrder(t *testing.T) {
+	a, b, c := &fakeSource{name: "a"}, &fakeSource{name: "b"}, &fakeSource{name: "c"}
+	ws := []content.Weighted{
+		content.NewWeighted(a, 3),
+		content.NewWeighted(b, 1),
+		content.NewWeighted(c, 2),
+	}
+
+	tests := []struct {
+		name  string
+		draws []int // what intN returns, one per position
+		want  []provider.Source
+	}{
+		// total 6: a covers 0-2, b 3, c 4-5
+		{"heaviest first", []int{0, 0, 0}, []provider.Source{a, b, c}},
+		{"upper end of a's share", []int{2, 0, 0}, []provider.Source{a, b, c}},
+		{"light source drawn", []int{3, 0, 0}, []provider.Source{b, a, c}},
+		{"rest keeps weights", []int{4, 3, 0}, []provider.Source{c, b, a}},
+		{"last of the rest", []int{5, 2, 0}, []provider.Source{c, a, b}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var totals []int
+			draw := 0
+			intN := func(n int) int {
+				totals = append(totals, n)
+				v := tc.draws[draw]
+				draw++
+				return v
+			}
+
+			assert.Equal(t, tc.want, content.Order(ws, intN))
+			assert.Equal(t, 6, totals[0], "first draw spans all weights")
+		})
+	}
+}
+
+func TestOrder_Shares(t *testing.T) {
+	a, b := &fakeSource{name: "a"}, &fakeSource{name: "b"}
+	ws := []content.Weighted{content.NewWeighted(a, 3), content.NewWeighted(b, 1)}
+
+	// walking every draw of the first position once gives the exact shares
+	first := map[provider.Source]int{}
+	for n := range 4 {
+		got := content.Order(ws, func(total int) int {
+			if total == 4 {
+				return n
+			}
+			return 0
+		})
+		first[got[0]]++
+	}
+	assert.Equal(t, 3, first[a])
+	assert.Equal(t, 1, first[b])
+}
+
+func TestNext(t *testing.T) {
+	ctx := context.Background()
+	item := &provider.Content{Key: "repo:1"}
+
+	t.Run("empty source falls through", func(t *testing.T) {
+		empty, full := &fakeSource{name: "empty"}, &fakeSource{name: "full", item: item}
+
+		got, err := content.Next(ctx, []provider.Source{empty, full})
+		require.NoError(t, err)
+		assert.Same(t, item, got)
+		assert.True(t, full.seen)
+	})
+
+	t.Run("failing source falls through", func(t *testing.T) {
+		failing, full := &fakeSource{name: "failing", err: errors.New("boom")}, &fakeSource{name: "full", item: item}
+
+		got, err := content.Next(ctx, []provider.Source{failing, full})
+		require.NoError(t, err)
+		assert.Same(t, item, got)
+	})
+
+	t.Run("first candidate wins", func(t *testing.T) {
+		first, second := &fakeSource{name: "first", item: item}, &fakeSource{name: "second", item: &provider.Content{Key: "repo:2"}}
+
+		got, err := content.Next(ctx, []provider.Source{first, second})
+		require.NoError(t, err)
+		assert.Same(t, item, got)
+		assert.False(t, second.seen, "later sources aren't asked")
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		got, err := content.Next(ctx, []provider.Source{&fakeSource{}, &fakeSource{}})
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("failure without candidate", func(t *testing.T) {
+		got, err := content.Next(ctx, []provider.Source{&fakeSource{}, &fakeSource{err: errors.New("boom")}})
+		require.ErrorIs(t, err, content.ErrCouldNotContent)
+		assert.Nil(t, got)
+	})
+}
+
+// fakeResolver answers lookups from maps, unknown entries fail. The
+// round trip behind VerifiedHandle is tested in the bluesky package.
+type fakeResolver struct {
+	dids    map[string]string // handle to DID
+	handles map[string]string // DID to its verified handle
+}
+
+func (f fakeResolver) ResolveHandle(_ context.Context, handle string) (string, error) {
+	if did, ok := f.dids[handle]; ok {
+		return did, nil
+	}
+	return "", errors.New("unable to resolve handle")
+}
+
+func (f fakeResolver) VerifiedHandle(_ context.Context, did string) (string, error) {
+	if handle, ok := f.handles[did]; ok {
+		return handle, nil
+	}
+	return "", errors.New("handle doesn't resolve back")
+}
+
+func TestCredit(t *testing.T) {
+	r := fakeResolver{
+		dids:    map[string]string{"octo.bsky.social": "did:plc:octo"},
+		handles: map[string]string{"did:plc:octo": "octo.bsky.social"},
+	}
+
+	tests := []struct {
+		name       string
+		author     provider.Author
+		wantAuthor string
+		wantDID    string
+	}{
+		{"forge login only", provider.Author{Login: "octo"}, "@octo", ""},
+		{"handle", provider.Author{Login: "octo", BlueskyHandle: "octo.bsky.social"}, "@octo.bsky.social", "did:plc:octo"},
+		{"unresolvable handle", provider.Author{Login: "octo", BlueskyHandle: "gone.bsky.social"}, "@octo", ""},
+		{"DID with verified handle", provider.Author{Login: "octo", BlueskyDID: "did:plc:octo"}, "@octo.bsky.social", "did:plc:octo"},
+		{"DID without verified handle", provider.Author{Login: "octo", BlueskyDID: "did:plc:spoofed"}, "@octo", ""},
+		{"DID without forge login", provider.Author{BlueskyDID: "did:plc:octo"}, "@octo.bsky.social", "did:plc:octo"},
+		{"nobody", provider.Author{}, "", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			author, did := content.Credit(context.Background(), r, tc.author)
+			assert.Equal(t, tc.wantAuthor, author)
+			assert.Equal(t, tc.wantDID, did)
+		})
+	}
+}
+
+func TestGrowthPeriod(t *testing.T) {
+	tests := []struct {
+		window time.Duration
+		want   string
+	}{
+		{30 * time.Minute, "in the last hour"},
+		{time.Hour, "in the last hour"},
+		{12 * time.Hour, "in 12 hours"},
+		{24 * time.Hour, "today"},
+		{36 * time.Hour, "today"},
+		{3 * 24 * time.Hour, "in 3 days"},
+		{7 * 24 * time.Hour, "this week"},
+		{30 * 24 * time.Hour, "in 30 days"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.window.String(), func(t *testing.T) {
+			assert.Equal(t, tc.want, content.GrowthPeriod(tc.window))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package content
+
+import (
+	"time"
+
+	ghprovider "github.com/till/golangoss-bluesky/internal/provider"
+)
+
+// Weighted pairs sources with their weights for Order.
+type Weighted = weightedSource
+
+func NewWeighted(src ghprovider.Source, weight int) Weighted {
+	return weightedSource{src: src, weight: weight}
+}
+
+// Scheduled is a started campaign without sources, for Due.
+type Scheduled = campaign
+
+func NewScheduled(c Campaign) *Scheduled {
+	return &campaign{Campaign: c}
+}
+
+// NewRotation is a campaign asking submissions, then the weighted sources.
+func NewRotation(submissions ghprovider.Source, ws ...Weighted) *Scheduled {
+	return &campaign{submissions: submissions, sources: ws}
+}
+
+// Candidates returns the sources in the order they're asked.
+func (c *campaign) Candidates(intN func(int) int) []ghprovider.Source { return c.candidates(intN) }
+
+// NextAt returns when the campaign is due.
+func (c *campaign) NextAt() time.Time { return c.next }
+
+// Schedule plans the campaign's next post one interval after now.
+func (c *campaign) Schedule(now time.Time) { c.schedule(now) }
+
+var (
+	Order        = order
+	Next         = next
+	Credit       = credit
+	Due          = due
+	Namespace    = namespace
+	GrowthPeriod = growthPeriod
+)
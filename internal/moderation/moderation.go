@@ -0,0 +1,270 @@
+// Package moderation holds rendered posts until a moderator decides on
+// them. Items live in the cache bucket as "moderation:<id>", the id being
+// the time they were queued, so listing the prefix returns them oldest
+// first. The moderator's decision is recorded on the item; the posting loop
+// claims the item in its next cycle, acts on the decision and then removes
+// it. A claimed item takes no more decisions, so a late one can't bring
+// back an item that was already posted.
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluesky-social/indigo/api/bsky"
+	"github.com/go-redis/redis/v8"
+	"github.com/till/golangoss-bluesky/internal/bluesky"
+)
+
+const prefix = "moderation:"
+
+// DefaultExpiry is how long an item waits for a decision unless the queue
+// is configured otherwise.
+const DefaultExpiry = 48 * time.Hour
+
+// grace keeps expired items in the cache a while longer, so the posting
+// loop sees them expire and can clean up after them.
+const grace = 24 * time.Hour
+
+// idLayout sorts lexicographically in time order.
+const idLayout = "20060102T150405.000000000Z"
+
+var (
+	// ErrNotFound is returned for an unknown, expired or already processed
+	// item.
+	ErrNotFound = errors.New("moderation item not found")
+	// ErrInvalidText is returned when an edited text can't be posted.
+	ErrInvalidText = errors.New("invalid post text")
+)
+
+// Status is the moderator's decision.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusSkipped  Status = "skipped"
+	// StatusBlocked skips the item and keeps its repo from being picked
+	// again.
+	StatusBlocked Status = "blocked"
+	// StatusPosting marks an item the posting loop claimed to act on its
+	// decision.
+	StatusPosting Status = "posting"
+)
+
+// Cache is the subset of cache.ClientS3 the queue uses.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value any, exp time.Duration) error
+	Del(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Item is a post awaiting a decision.
+type Item struct {
+	ID       string `json:"id"`
+	Campaign string `json:"campaign,omitempty"`
+	// Key is the repo's cache key in its campaign, e.g. "repo:<id>".
+	Key  string         `json:"key"`
+	Repo string         `json:"repo"` // "owner/name"
+	URL  string         `json:"url"`
+	Post *bsky.FeedPost `json:"post"`
+
+	Status    Status    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the item ran out of time waiting for a decision.
+// Decided items don't expire.
+func (i Item) Expired(now time.Time) bool {
+	return i.Status == StatusPending && now.After(i.ExpiresAt)
+}
+
+// Queue is the moderation queue. The admin page and the posting loop must
+// share one, its lock keeps decisions and claims apart.
+type Queue struct {
+	cache  Cache
+	expiry time.Duration
+
+	mu sync.Mutex
+}
+
+// NewQueue returns a queue backed by c whose items expire after expiry,
+// DefaultExpiry when it's not positive.
+func NewQueue(c Cache, expiry time.Duration) *Queue {
+	if expiry <= 0 {
+		expiry = DefaultExpiry
+	}
+	return &Queue{cache: c, expiry: expiry}
+}
+
+// Add queues item for a decision. ID, status and times are set by the queue.
+func (q *Queue) Add(ctx context.Context, item Item) (Item, error) {
+	now := time.Now().UTC()
+	item.ID = now.Format(idLayout)
+	item.Status = StatusPending
+	item.CreatedAt, item.ExpiresAt = now, now.Add(q.expiry)
+	if err := q.save(ctx, item); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// List returns all items, decided or not, oldest first.
+func (q *Queue) List(ctx context.Context) ([]Item, error) {
+	keys, err := q.cache.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(keys)
+
+	out := make([]Item, 0, len(keys))
+	for _, k := range keys {
+		item, err := q.Get(ctx, strings.TrimPrefix(k, prefix))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, nil
+}
+
+// Pending returns the items waiting for a decision, oldest first.
+func (q *Queue) Pending(ctx context.Context) ([]Item, error) {
+	items, err := q.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return slices.DeleteFunc(items, func(i Item) bool {
+		return i.Status != StatusPending || i.Expired(now)
+	}), nil
+}
+
+// Get returns the item with id.
+func (q *Queue) Get(ctx context.Context, id string) (Item, error) {
+	raw, err := q.cache.Get(ctx, prefix+id)
+	if err == redis.Nil {
+		return Item{}, fmt.Errorf("%s: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return Item{}, err
+	}
+	var item Item
+	if err := json.Unmarshal([]byte(raw), &item); err != nil {
+		return Item{}, fmt.Errorf("moderation item %s: %w", id, err)
+	}
+	return item, nil
+}
+
+// Approve has the item posted in the next cycle. A non-empty text replaces
+// the post's text.
+func (q *Queue) Approve(ctx context.Context, id, text string) error {
+	return q.decide(ctx, id, StatusApproved, func(item *Item) error {
+		if text == "" || text == item.Post.Text {
+			return nil
+		}
+		if err := bluesky.EditText(item.Post, text); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidText, err)
+		}
+		return nil
+	})
+}
+
+// Skip drops the item without posting it.
+func (q *Queue) Skip(ctx context.Context, id string) error {
+	return q.decide(ctx, id, StatusSkipped, nil)
+}
+
+// Block drops the item and keeps its repo from being picked again.
+func (q *Queue) Block(ctx context.Context, id string) error {
+	return q.decide(ctx, id, StatusBlocked, nil)
+}
+
+// Remove deletes the item, once its decision was acted on.
+func (q *Queue) Remove(ctx context.Context, id string) error {
+	return q.cache.Del(ctx, prefix+id)
+}
+
+// Claim takes the item off the moderator's hands once it's decided or
+// expired: it's marked StatusPosting, so later decisions fail with
+// ErrNotFound, and returned with the decision it was claimed on. ok is
+// false for an item still waiting for a decision. Act on the decision
+// after Claim returns, then Remove the item, or Unclaim it when acting
+// failed.
+func (q *Queue) Claim(ctx context.Context, id string) (item Item, ok bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err = q.Get(ctx, id)
+	if err != nil {
+		return Item{}, false, err
+	}
+	switch {
+	case item.Status == StatusPosting:
+		return Item{}, false, fmt.Errorf("%s already claimed: %w", id, ErrNotFound)
+	case item.Status == StatusPending && !item.Expired(time.Now()):
+		return item, false, nil
+	}
+
+	claimed := item
+	claimed.Status = StatusPosting
+	if err := q.save(ctx, claimed); err != nil {
+		return Item{}, false, err
+	}
+	return item, true, nil
+}
+
+// Unclaim puts a claimed item back with its decision, to be acted on in a
+// later cycle.
+func (q *Queue) Unclaim(ctx context.Context, item Item) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.save(ctx, item)
+}
+
+func (q *Queue) decide(ctx context.Context, id string, status Status, edit func(*Item) error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, err := q.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if item.Status == StatusPosting {
+		return fmt.Errorf("%s already claimed: %w", id, ErrNotFound)
+	}
+	if item.Expired(time.Now()) {
+		return fmt.Errorf("%s expired: %w", id, ErrNotFound)
+	}
+	if edit != nil {
+		if err := edit(&item); err != nil {
+			return err
+		}
+	}
+	item.Status = status
+	return q.save(ctx, item)
+}
+
+func (q *Queue) save(ctx context.Context, item Item) error {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("moderation item %s: %w", item.ID, err)
+	}
+	ttl := time.Until(item.ExpiresAt) + grace
+	if err := q.cache.Set(ctx, prefix+item.ID, string(raw), ttl); err != nil {
+		return fmt.Errorf("moderation item %s: %w", item.ID, err)
+	}
+	return nil
+}
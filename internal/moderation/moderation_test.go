@@ -0,0 +1,159 @@
+package moderation_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/bluesky"
+	"github.com/till/golangoss-bluesky/internal/moderation"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func item(repo string) moderation.Item {
+	return moderation.Item{
+		Key:  "repo:1",
+		Repo: repo,
+		URL:  "https://github.com/" + repo,
+		Post: bluesky.PostRecord(bluesky.PostInput{
+			Title:       "repo",
+			Description: "does things",
+			URL:         "https://github.com/" + repo,
+			Stars:       "1 ⭐️",
+			Hashtags:    "#go",
+		}),
+	}
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	q := moderation.NewQueue(testutil.MemCache{}, time.Hour)
+
+	first, err := q.Add(ctx, item("owner/first"))
+	require.NoError(t, err)
+	second, err := q.Add(ctx, item("owner/second"))
+	require.NoError(t, err)
+	assert.Equal(t, moderation.StatusPending, first.Status)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), first.ExpiresAt, time.Minute)
+
+	pending, err := q.Pending(ctx)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "owner/first", pending[0].Repo)
+	// the post survives the round trip through the cache
+	assert.Equal(t, first.Post.Text, pending[0].Post.Text)
+	require.Len(t, pending[0].Post.Facets, 2)
+	assert.Equal(t, "https://github.com/owner/first", pending[0].Post.Facets[0].Features[0].RichtextFacet_Link.Uri)
+
+	require.NoError(t, q.Approve(ctx, first.ID, "repo is great\n\n#go"))
+	require.NoError(t, q.Block(ctx, second.ID))
+
+	pending, err = q.Pending(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	all, err := q.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, moderation.StatusApproved, all[0].Status)
+	assert.Equal(t, "repo is great\n\n#go", all[0].Post.Text)
+	assert.Equal(t, moderation.StatusBlocked, all[1].Status)
+
+	require.NoError(t, q.Remove(ctx, first.ID))
+	_, err = q.Get(ctx, first.ID)
+	assert.ErrorIs(t, err, moderation.ErrNotFound)
+	assert.ErrorIs(t, q.Skip(ctx, first.ID), moderation.ErrNotFound)
+}
+
+func TestQueue_Expiry(t *testing.T) {
+	ctx := context.Background()
+	q := moderation.NewQueue(testutil.MemCache{}, time.Nanosecond)
+
+	it, err := q.Add(ctx, item("owner/repo"))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	pending, err := q.Pending(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.ErrorIs(t, q.Approve(ctx, it.ID, ""), moderation.ErrNotFound)
+
+	all, err := q.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.True(t, all[0].Expired(time.Now()))
+}
+
+func TestQueue_Claim(t *testing.T) {
+	ctx := context.Background()
+	q := moderation.NewQueue(testutil.MemCache{}, time.Hour)
+
+	approved, err := q.Add(ctx, item("owner/approved"))
+	require.NoError(t, err)
+	require.NoError(t, q.Approve(ctx, approved.ID, ""))
+	pending, err := q.Add(ctx, item("owner/pending"))
+	require.NoError(t, err)
+
+	_, ok, err := q.Claim(ctx, pending.ID)
+	require.NoError(t, err)
+	assert.False(t, ok, "pending items can't be claimed")
+
+	// the loop claims the item, then posts it without holding the lock
+	claimed, ok, err := q.Claim(ctx, approved.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, moderation.StatusApproved, claimed.Status)
+
+	// meanwhile the moderator acts on it again, without waiting
+	require.ErrorIs(t, q.Approve(ctx, approved.ID, ""), moderation.ErrNotFound)
+	require.ErrorIs(t, q.Skip(ctx, approved.ID), moderation.ErrNotFound)
+	_, _, err = q.Claim(ctx, approved.ID)
+	require.ErrorIs(t, err, moderation.ErrNotFound)
+
+	require.NoError(t, q.Remove(ctx, approved.ID))
+
+	// the late decision didn't bring the item back to be posted again
+	all, err := q.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, pending.ID, all[0].ID)
+}
+
+func TestQueue_Unclaim(t *testing.T) {
+	ctx := context.Background()
+	q := moderation.NewQueue(testutil.MemCache{}, time.Hour)
+
+	it, err := q.Add(ctx, item("owner/repo"))
+	require.NoError(t, err)
+	require.NoError(t, q.Block(ctx, it.ID))
+
+	claimed, ok, err := q.Claim(ctx, it.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// acting on it failed, the decision is kept for the next cycle
+	require.NoError(t, q.Unclaim(ctx, claimed))
+	got, err := q.Get(ctx, it.ID)
+	require.NoError(t, err)
+	assert.Equal(t, moderation.StatusBlocked, got.Status)
+
+	_, ok, err = q.Claim(ctx, it.ID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestQueue_ClaimExpired(t *testing.T) {
+	ctx := context.Background()
+	q := moderation.NewQueue(testutil.MemCache{}, time.Nanosecond)
+
+	it, err := q.Add(ctx, item("owner/repo"))
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+
+	claimed, ok, err := q.Claim(ctx, it.ID)
+	require.NoError(t, err)
+	require.True(t, ok, "expired items are claimed to be cleaned up")
+	assert.True(t, claimed.Expired(time.Now()))
+}
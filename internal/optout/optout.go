@@ -0,0 +1,97 @@
+// Package optout keeps track of maintainers who asked not to be featured.
+// Entries are keyed by GitHub login and live in the cache bucket as
+// "optout:<login>", next to the "repo:<id>" keys.
+package optout
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/till/golangoss-bluesky/internal/cache"
+)
+
+const prefix = "optout:"
+
+// repliesKey holds when the newest notification looked at for opt-out
+// replies was indexed. It's outside prefix, so List doesn't take it for a
+// login.
+const repliesKey = "optout-replies:seen"
+
+// Cache is the subset of cache.ClientS3 the store uses.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value any, exp time.Duration) error
+	Del(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Store is the opt-out registry.
+type Store struct {
+	cache Cache
+}
+
+// NewStore returns a store backed by c.
+func NewStore(c Cache) Store {
+	return Store{cache: c}
+}
+
+// Add opts login out. The note records where the request came from.
+func (s Store) Add(ctx context.Context, login, note string) error {
+	return s.cache.Set(ctx, key(login), note, cache.NoExpiry)
+}
+
+// Remove opts login back in.
+func (s Store) Remove(ctx context.Context, login string) error {
+	return s.cache.Del(ctx, key(login))
+}
+
+// Has reports whether login opted out.
+func (s Store) Has(ctx context.Context, login string) (bool, error) {
+	_, err := s.cache.Get(ctx, key(login))
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// List returns all logins that opted out.
+func (s Store) List(ctx context.Context) ([]string, error) {
+	keys, err := s.cache.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	logins := make([]string, 0, len(keys))
+	for _, k := range keys {
+		logins = append(logins, strings.TrimPrefix(k, prefix))
+	}
+	return logins, nil
+}
+
+// RepliesSeen returns when the newest notification looked at for opt-out
+// replies was indexed, the zero time before the first look.
+func (s Store) RepliesSeen(ctx context.Context) (time.Time, error) {
+	raw, err := s.cache.Get(ctx, repliesKey)
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, raw)
+}
+
+// SetRepliesSeen records when the newest notification looked at was
+// indexed.
+func (s Store) SetRepliesSeen(ctx context.Context, t time.Time) error {
+	return s.cache.Set(ctx, repliesKey, t.UTC().Format(time.RFC3339Nano), cache.NoExpiry)
+}
+
+// GitHub logins are case-insensitive.
+func key(login string) string {
+	return prefix + strings.ToLower(login)
+}
@@ -0,0 +1,54 @@
+package optout_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/optout"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	cache := testutil.MemCache{"repo:1": true}
+	s := optout.NewStore(cache)
+
+	require.NoError(t, s.Add(ctx, "SomeMaintainer", "cli"))
+	assert.Equal(t, "cli", cache["optout:somemaintainer"])
+
+	out, err := s.Has(ctx, "somemaintainer")
+	require.NoError(t, err)
+	assert.True(t, out)
+
+	logins, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"somemaintainer"}, logins)
+
+	require.NoError(t, s.Remove(ctx, "SOMEMAINTAINER"))
+	out, err = s.Has(ctx, "SomeMaintainer")
+	require.NoError(t, err)
+	assert.False(t, out)
+}
+
+func TestStore_RepliesSeen(t *testing.T) {
+	ctx := context.Background()
+	cache := testutil.MemCache{}
+	s := optout.NewStore(cache)
+
+	seen, err := s.RepliesSeen(ctx)
+	require.NoError(t, err)
+	assert.True(t, seen.IsZero())
+
+	at := time.Date(2026, 10, 1, 14, 0, 0, 500, time.UTC)
+	require.NoError(t, s.SetRepliesSeen(ctx, at))
+	seen, err = s.RepliesSeen(ctx)
+	require.NoError(t, err)
+	assert.True(t, at.Equal(seen))
+
+	logins, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, logins, "the cursor isn't an opt-out")
+}
@@ -0,0 +1,128 @@
+// Package policy holds the hand-maintained rules on top of the automatic
+// filters: owners, repos and keywords the bot must never feature, exceptions
+// to those, and repos to favor when ranking. Rules are loaded from a JSON
+// file at startup.
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+)
+
+// defaultBoost is the score multiplier when the file doesn't set one.
+const defaultBoost = 2
+
+// Policy is the content of the policy file, e.g.
+//
+//	{
+//	  "deny":  {"owners": ["spammer"], "repos": ["*/awesome-*"], "keywords": ["crypto wallet"]},
+//	  "allow": {"repos": ["avelino/awesome-go"]},
+//	  "boost": {"owners": ["golang"], "factor": 2}
+//	}
+type Policy struct {
+	// Deny blocks matching repos permanently.
+	Deny Rules `json:"deny"`
+	// Allow exempts matching repos from Deny.
+	Allow Rules `json:"allow"`
+	// Boost multiplies the score of matching repos by Boost.Factor.
+	Boost Rules `json:"boost"`
+}
+
+// Rules match a repo by owner, by "owner/name" glob (see path.Match) or by
+// keyword. All comparisons are case-insensitive. Keywords match substrings
+// of the description and whole topics.
+type Rules struct {
+	Owners   []string `json:"owners"`
+	Repos    []string `json:"repos"`
+	Keywords []string `json:"keywords"`
+	// Factor is only read on Boost.
+	Factor float64 `json:"factor,omitempty"`
+}
+
+// Repo is what the rules are matched against.
+type Repo struct {
+	Owner       string
+	Name        string
+	Description string
+	Topics      []string
+}
+
+// Load reads a policy file. An empty path yields an empty policy.
+func Load(file string) (Policy, error) {
+	var p Policy
+	if file == "" {
+		return p, nil
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return p, fmt.Errorf("read policy: %w", err)
+	}
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return p, fmt.Errorf("parse policy %s: %w", file, err)
+	}
+	for _, glob := range slices.Concat(p.Deny.Repos, p.Allow.Repos, p.Boost.Repos) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return p, fmt.Errorf("policy %s: repo pattern %q: %w", file, glob, err)
+		}
+	}
+	return p, nil
+}
+
+// Denied reports whether r is blocked, and by which rule.
+func (p Policy) Denied(r Repo) (bool, string) {
+	rule := p.Deny.match(r)
+	if rule == "" {
+		return false, ""
+	}
+	if p.Allow.match(r) != "" {
+		return false, ""
+	}
+	return true, rule
+}
+
+// BoostFactor returns the score multiplier for r, 1 when no boost applies.
+func (p Policy) BoostFactor(r Repo) float64 {
+	if p.Boost.match(r) == "" {
+		return 1
+	}
+	if p.Boost.Factor > 0 {
+		return p.Boost.Factor
+	}
+	return defaultBoost
+}
+
+// match returns a description of the first rule matching r, or "".
+func (rs Rules) match(r Repo) string {
+	owner := strings.ToLower(r.Owner)
+	fullName := owner + "/" + strings.ToLower(r.Name)
+
+	for _, o := range rs.Owners {
+		if strings.ToLower(o) == owner {
+			return "owner " + o
+		}
+	}
+	for _, glob := range rs.Repos {
+		if ok, _ := path.Match(strings.ToLower(glob), fullName); ok {
+			return "repo " + glob
+		}
+	}
+
+	desc := strings.ToLower(r.Description)
+	for _, kw := range rs.Keywords {
+		k := strings.ToLower(kw)
+		if strings.Contains(desc, k) {
+			return "keyword " + kw
+		}
+		for _, t := range r.Topics {
+			if strings.ToLower(t) == k {
+				return "topic " + kw
+			}
+		}
+	}
+	return ""
+}
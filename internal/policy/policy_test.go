@@ -0,0 +1,71 @@
+package policy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/policy"
+)
+
+func TestPolicy_Denied(t *testing.T) {
+	p := policy.Policy{
+		Deny: policy.Rules{
+			Owners:   []string{"Spammer"},
+			Repos:    []string{"*/awesome-*", "acme/secret"},
+			Keywords: []string{"crypto wallet", "malware"},
+		},
+		Allow: policy.Rules{Repos: []string{"avelino/awesome-go"}},
+	}
+
+	testCases := []struct {
+		name   string
+		repo   policy.Repo
+		denied bool
+		rule   string
+	}{
+		{"clean", policy.Repo{Owner: "till", Name: "golangoss-bluesky", Description: "a bot"}, false, ""},
+		{"owner", policy.Repo{Owner: "spammer", Name: "anything"}, true, "owner Spammer"},
+		{"repo glob", policy.Repo{Owner: "x", Name: "Awesome-Things"}, true, "repo */awesome-*"},
+		{"exact repo", policy.Repo{Owner: "ACME", Name: "secret"}, true, "repo acme/secret"},
+		{"allowed despite glob", policy.Repo{Owner: "avelino", Name: "awesome-go"}, false, ""},
+		{"description keyword", policy.Repo{Owner: "x", Name: "y", Description: "Drain any Crypto Wallet fast"}, true, "keyword crypto wallet"},
+		{"topic keyword", policy.Repo{Owner: "x", Name: "y", Topics: []string{"Malware"}}, true, "topic malware"},
+		{"topic substring doesn't match", policy.Repo{Owner: "x", Name: "y", Topics: []string{"anti-malware"}}, false, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			denied, rule := p.Denied(tc.repo)
+			assert.Equal(t, tc.denied, denied)
+			assert.Equal(t, tc.rule, rule)
+		})
+	}
+}
+
+func TestPolicy_BoostFactor(t *testing.T) {
+	p := policy.Policy{Boost: policy.Rules{Owners: []string{"golang"}}}
+	assert.Equal(t, 2.0, p.BoostFactor(policy.Repo{Owner: "golang", Name: "tools"}))
+	assert.Equal(t, 1.0, p.BoostFactor(policy.Repo{Owner: "other", Name: "tools"}))
+
+	p.Boost.Factor = 3
+	assert.Equal(t, 3.0, p.BoostFactor(policy.Repo{Owner: "golang", Name: "tools"}))
+}
+
+func TestLoad(t *testing.T) {
+	p, err := policy.Load("")
+	require.NoError(t, err)
+	assert.Empty(t, p.Deny.Owners)
+
+	file := filepath.Join(t.TempDir(), "policy.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"deny": {"owners": ["spammer"]}}`), 0o600))
+	p, err = policy.Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"spammer"}, p.Deny.Owners)
+
+	require.NoError(t, os.WriteFile(file, []byte(`{"deny": {"repos": ["[oops"]}}`), 0o600))
+	_, err = policy.Load(file)
+	require.Error(t, err)
+}
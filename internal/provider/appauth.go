@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+const (
+	// appJWTLifetime stays under GitHub's 10 minute maximum.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates iat in case our clock is ahead of GitHub's.
+	appJWTClockSkew = time.Minute
+	// tokenRefreshMargin renews installation tokens (valid for an hour) this
+	// long before they expire, so a request never goes out with a stale one.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// AppCredentials identify a GitHub App installation.
+type AppCredentials struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     []byte // PEM, as downloaded from the app settings
+}
+
+// AppTokenSource mints installation tokens for a GitHub App and refreshes
+// them before they expire. Safe for concurrent use.
+type AppTokenSource struct {
+	creds AppCredentials
+	key   *rsa.PrivateKey
+	apps  *github.AppsService
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// NewAppTokenSource parses the private key and prepares the client used to
+// exchange app JWTs for installation tokens. baseURL points at the API,
+// empty means api.github.com.
+func NewAppTokenSource(creds AppCredentials, baseURL string) (*AppTokenSource, error) {
+	key, err := parsePrivateKey(creds.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &AppTokenSource{creds: creds, key: key}
+
+	opts := []github.ClientOptionsFunc{github.WithTransport(roundTripperFunc(s.jwtRoundTrip))}
+	if baseURL != "" {
+		opts = append(opts, github.WithURLs(&baseURL, &baseURL))
+	}
+	gh, err := github.NewClient(opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.apps = gh.Apps
+
+	return s, nil
+}
+
+// Token returns a valid installation token, minting a new one when the
+// current one is about to expire.
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Until(s.expiry) > tokenRefreshMargin {
+		return s.token, nil
+	}
+
+	tok, _, err := s.apps.CreateInstallationToken(ctx, s.creds.InstallationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("create installation token: %w", err)
+	}
+	s.token = tok.GetToken()
+	s.expiry = tok.GetExpiresAt().Time
+	return s.token, nil
+}
+
+// Transport authenticates every request passing through next with the
+// current installation token.
+func (s *AppTokenSource) Transport(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		tok, err := s.Token(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+tok)
+		return next.RoundTrip(req)
+	})
+}
+
+// jwtRoundTrip authenticates as the app itself, which is only good for the
+// token exchange.
+func (s *AppTokenSource) jwtRoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := s.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// jwt builds the RS256-signed token GitHub expects from an app.
+func (s *AppTokenSource) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.creds.AppID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, sum[:])
+	if err != nil {
+		return "", fmt.Errorf("sign app jwt: %w", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// parsePrivateKey reads a PKCS#1 (what GitHub hands out) or PKCS#8 RSA key.
+func parsePrivateKey(pemBytes []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("github app private key: no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("github app private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("github app private key: not an RSA key")
+	}
+	return key, nil
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
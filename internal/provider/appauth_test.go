@@ -0,0 +1,140 @@
+package provider_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/provider"
+)
+
+// fakeTokenEndpoint serves installation tokens with the given lifetimes, one
+// per request, after checking the app JWT against key.
+func fakeTokenEndpoint(t *testing.T, key *rsa.PrivateKey, lifetimes ...time.Duration) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/app/installations/42/access_tokens", r.URL.Path)
+		if !verifyAppJWT(t, &key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")) {
+			http.Error(w, "invalid app JWT", http.StatusUnauthorized)
+			return
+		}
+
+		// runs outside the test goroutine, so assert and answer instead of
+		// require
+		if !assert.Less(t, calls, len(lifetimes), "unexpected token request") {
+			http.Error(w, "unexpected token request", http.StatusInternalServerError)
+			return
+		}
+		exp := time.Now().Add(lifetimes[calls]).UTC()
+		calls++
+
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("ghs_%d", calls),
+			"expires_at": exp.Format(time.RFC3339),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+// verifyAppJWT reports whether jwt is signed with pub and carries the
+// app's claims. It's called from handlers, so it only asserts.
+func verifyAppJWT(t *testing.T, pub *rsa.PublicKey, jwt string) bool {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if !assert.Len(t, parts, 3) {
+		return false
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if !assert.NoError(t, err) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !assert.NoError(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig)) {
+		return false
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if !assert.NoError(t, err) {
+		return false
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	if !assert.NoError(t, json.Unmarshal(raw, &claims)) {
+		return false
+	}
+	return assert.Equal(t, "7", claims.Iss) &&
+		assert.LessOrEqual(t, claims.Exp-claims.Iat, int64(10*60))
+}
+
+func appCredentials(t *testing.T) (provider.AppCredentials, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return provider.AppCredentials{AppID: 7, InstallationID: 42, PrivateKey: pemKey}, key
+}
+
+func TestAppTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	creds, key := appCredentials(t)
+	// the first token is already inside the refresh margin, the second isn't
+	srv, calls := fakeTokenEndpoint(t, key, 2*time.Minute, time.Hour)
+
+	src, err := provider.NewAppTokenSource(creds, srv.URL+"/")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	for _, want := range []string{"ghs_1", "ghs_2", "ghs_2"} {
+		tok, err := src.Token(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, want, tok)
+	}
+	assert.Equal(t, 2, *calls)
+}
+
+func TestAppTokenSource_Transport(t *testing.T) {
+	creds, key := appCredentials(t)
+	tokens, _ := fakeTokenEndpoint(t, key, time.Hour)
+
+	var auth string
+	api := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	t.Cleanup(api.Close)
+
+	src, err := provider.NewAppTokenSource(creds, tokens.URL+"/")
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: src.Transport(http.DefaultTransport)}
+	resp, err := client.Get(api.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, "Bearer ghs_1", auth)
+}
+
+func TestNewAppTokenSource_InvalidKey(t *testing.T) {
+	_, err := provider.NewAppTokenSource(provider.AppCredentials{AppID: 7, PrivateKey: []byte("nope")}, "")
+	require.Error(t, err)
+}
@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/go-github/v90/github"
+)
+
+const (
+	// curatedRefresh is how long a parsed list is used before it's read
+	// again.
+	curatedRefresh = 24 * time.Hour
+	// curatedLookupsPerCycle caps the repo lookups per Next, most entries
+	// are looked up once and then skipped via their cache key.
+	curatedLookupsPerCycle = 5
+	// curatedRecheck is how long a rejected entry is skipped before it's
+	// looked up again.
+	curatedRecheck = 30 * 24 * time.Hour
+)
+
+var (
+	mdHeading  = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*$`)
+	awesomeRow = regexp.MustCompile(`^\s*[-*+]\s+\[([^\]]+)\]\(([^)\s]+)\)\s*(?:[-–—:]\s*)?(.*)$`)
+)
+
+// AwesomeEntry is a GitHub repo listed in an awesome list.
+type AwesomeEntry struct {
+	// Category is the section the repo is listed in, e.g. "Command Line".
+	Category    string
+	Name        string // link text
+	Owner       string
+	Repo        string
+	Description string
+}
+
+// FullName returns "owner/repo".
+func (e AwesomeEntry) FullName() string {
+	return e.Owner + "/" + e.Repo
+}
+
+// ParseAwesome reads the entries of an awesome list in the awesome-go
+// layout: "## Category" sections of "- [name](url) - description" items.
+// Subsections count towards their category. Links to anything but a GitHub
+// repo are skipped, so are the table of contents and everything from the
+// "Resources" part on, which lists books and websites.
+func ParseAwesome(md string) []AwesomeEntry {
+	var (
+		out      []AwesomeEntry
+		category string
+		seen     = map[string]bool{}
+	)
+
+	sc := bufio.NewScanner(strings.NewReader(md))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			level, title := len(m[1]), strings.TrimSpace(m[2])
+			if level <= 2 && strings.EqualFold(title, "Resources") {
+				break
+			}
+			if level <= 2 {
+				category = title
+				if strings.EqualFold(title, "Contents") {
+					category = ""
+				}
+			}
+			continue
+		}
+		if category == "" {
+			continue
+		}
+
+		m := awesomeRow.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		owner, repo, ok := githubRepo(m[2])
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(owner + "/" + repo)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		out = append(out, AwesomeEntry{
+			Category:    category,
+			Name:        strings.TrimSpace(m[1]),
+			Owner:       owner,
+			Repo:        repo,
+			Description: strings.TrimSpace(m[3]),
+		})
+	}
+	return out
+}
+
+// githubRepo returns owner and name of a github.com repo URL. Links into a
+// repo, e.g. to a package of a monorepo, count as the repo.
+func githubRepo(raw string) (owner, repo string, ok bool) {
+	u, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(strings.TrimPrefix(u.Hostname(), "www."), "github.com") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+}
+
+// categoryTag turns a category into a hashtag: lowercase words joined by
+// dashes, without "and", e.g. "Authentication and OAuth" becomes
+// "authentication-oauth".
+func categoryTag(category string) string {
+	words := strings.FieldsFunc(strings.ToLower(category), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	words = slices.DeleteFunc(words, func(w string) bool { return w == "and" })
+	return strings.Join(words, "-")
+}
+
+func curatedKey(fullName string) string {
+	return "curated:" + strings.ToLower(fullName)
+}
+
+var _ Source = (*Curated)(nil)
+
+// Curated features repos from an awesome list. The list's entries are
+// looked up on GitHub one by one and go through the same checks as search
+// results; the category they're listed under becomes an extra hashtag.
+// Not safe for concurrent use.
+type Curated struct {
+	p Provider
+	// list is the GitHub repo ("owner/name") whose README is the list,
+	// file a local copy that's read instead when set.
+	list string
+	file string
+
+	entries []AwesomeEntry
+	loaded  time.Time
+}
+
+// NewCurated creates a source for the awesome list in the README of the
+// GitHub repo list, e.g. "avelino/awesome-go", or in file when set. p
+// looks up the entries.
+func NewCurated(p Provider, list, file string) (*Curated, error) {
+	if file == "" {
+		if owner, name, ok := strings.Cut(list, "/"); !ok || owner == "" || name == "" {
+			return nil, fmt.Errorf("curated list %q: want owner/name", list)
+		}
+	}
+	return &Curated{p: p, list: list, file: file}, nil
+}
+
+// Next returns an entry of the list that passes the checks and wasn't
+// featured, or (nil, nil) when none was found within
+// curatedLookupsPerCycle lookups.
+func (c *Curated) Next(ctx context.Context) (*Content, error) {
+	entries, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lookups := 0
+	for _, i := range rand.Perm(len(entries)) {
+		e := entries[i]
+		key := curatedKey(e.FullName())
+		seen, err := isSeen(ctx, c.p.CacheClient, key)
+		if err != nil {
+			return nil, err
+		}
+		if seen {
+			continue
+		}
+
+		if lookups == curatedLookupsPerCycle || c.p.Rates.low(rateCore) {
+			return nil, nil
+		}
+		lookups++
+
+		item, reason, err := c.lookup(ctx, e)
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			return item, nil
+		}
+		if err := c.p.CacheClient.Set(ctx, key, reason, curatedRecheck); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// lookup fetches e from GitHub and checks it. It returns why e was
+// skipped when it doesn't qualify.
+func (c *Curated) lookup(ctx context.Context, e AwesomeEntry) (*Content, string, error) {
+	var repo *github.Repository
+	err := c.p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+		repo, resp, err = c.p.GitHubRepoClient.Get(ctx, e.Owner, e.Repo)
+		return resp, err
+	})
+	if isNotFound(err) {
+		return nil, "not found", nil
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("curated %s: %w", e.FullName(), err)
+	}
+
+	if reason := c.p.searchReject(repo); reason != "" {
+		slog.DebugContext(ctx, "rejected candidate", "repo", e.FullName(), "reason", reason)
+		return nil, reason, nil
+	}
+
+	item, err := c.p.check(ctx, repo)
+	if err != nil || item == nil {
+		return nil, "rejected", err
+	}
+	item.Hashtag = hashtags(c.p.Config, append([]string{categoryTag(e.Category)}, repo.Topics...))
+	slog.DebugContext(ctx, "picked curated candidate", "repo", e.FullName(), "category", e.Category)
+	return item, "", nil
+}
+
+// Enrich is the GitHub provider's.
+func (c *Curated) Enrich(ctx context.Context, item *Content) error {
+	return c.p.Enrich(ctx, item)
+}
+
+// MarkSeen is the GitHub provider's, plus the entry's own key so the list
+// doesn't offer it again.
+func (c *Curated) MarkSeen(ctx context.Context, item *Content) error {
+	if err := c.p.MarkSeen(ctx, item); err != nil {
+		return err
+	}
+	return markSeen(ctx, c.p.CacheClient, curatedKey(item.FullName))
+}
+
+// load returns the list's entries, reading it again once it's older than
+// curatedRefresh. A failed refresh keeps the entries read before.
+func (c *Curated) load(ctx context.Context) ([]AwesomeEntry, error) {
+	if c.entries != nil && time.Since(c.loaded) < curatedRefresh {
+		return c.entries, nil
+	}
+
+	md, err := c.read(ctx)
+	if err != nil {
+		if c.entries != nil {
+			slog.WarnContext(ctx, "refreshing curated list failed", "err", err)
+			return c.entries, nil
+		}
+		return nil, err
+	}
+
+	c.entries, c.loaded = ParseAwesome(md), time.Now()
+	slog.InfoContext(ctx, "loaded curated list", "entries", len(c.entries))
+	return c.entries, nil
+}
+
+func (c *Curated) read(ctx context.Context) (string, error) {
+	if c.file != "" {
+		raw, err := os.ReadFile(c.file)
+		if err != nil {
+			return "", fmt.Errorf("read curated list: %w", err)
+		}
+		return string(raw), nil
+	}
+
+	owner, name, _ := strings.Cut(c.list, "/")
+	var readme *github.RepositoryContent
+	err := c.p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+		readme, resp, err = c.p.GitHubRepoClient.GetReadme(ctx, owner, name, nil)
+		return resp, err
+	})
+	if err != nil {
+		return "", fmt.Errorf("curated list %s: %w", c.list, err)
+	}
+	md, err := readme.GetContent()
+	if err != nil {
+		return "", fmt.Errorf("decode curated list %s: %w", c.list, err)
+	}
+	return md, nil
+}
@@ -0,0 +1,118 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+const awesomeList = `# Awesome Go
+
+## Contents
+
+- [Command Line](#command-line)
+
+## Command Line
+
+_Libraries for building console applications._
+
+### Standard CLI
+
+- [cobra](https://github.com/spf13/cobra) - Commander for modern Go CLI interactions.
+- [urfave/cli](https://github.com/urfave/cli/tree/main/v3) - Simple, fast, and fun package for building command line apps in Go.
+- [website](https://example.com/cli) - Not a repo.
+
+**[⬆ back to top](#contents)**
+
+## Authentication and OAuth
+
+* [casbin](https://github.com/casbin/casbin): Authorization library that supports ACL, RBAC and ABAC.
+- [cobra again](https://github.com/spf13/cobra) - Listed twice.
+
+# Resources
+
+## Websites
+
+- [Awesome Go Website](https://github.com/avelino/awesome-go-web) - A website.
+`
+
+func TestParseAwesome(t *testing.T) {
+	entries := provider.ParseAwesome(awesomeList)
+	assert.Equal(t, []provider.AwesomeEntry{
+		{Category: "Command Line", Name: "cobra", Owner: "spf13", Repo: "cobra", Description: "Commander for modern Go CLI interactions."},
+		{Category: "Command Line", Name: "urfave/cli", Owner: "urfave", Repo: "cli", Description: "Simple, fast, and fun package for building command line apps in Go."},
+		{Category: "Authentication and OAuth", Name: "casbin", Owner: "casbin", Repo: "casbin", Description: "Authorization library that supports ACL, RBAC and ABAC."},
+	}, entries)
+}
+
+func TestCurated_Next(t *testing.T) {
+	readmeFetches := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/avelino/awesome-go/readme", func(w http.ResponseWriter, _ *http.Request) {
+		readmeFetches++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(awesomeList)),
+		})
+	})
+	pushed := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	repo := func(id int, owner, name string, extra map[string]any) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			r := map[string]any{
+				"id": id, "name": name, "full_name": owner + "/" + name,
+				"html_url":  "https://github.com/" + owner + "/" + name,
+				"language":  "Go",
+				"pushed_at": pushed,
+				"owner":     map[string]any{"login": owner},
+			}
+			for k, v := range extra {
+				r[k] = v
+			}
+			_ = json.NewEncoder(w).Encode(r)
+		}
+	}
+	mux.HandleFunc("/repos/spf13/cobra", repo(1, "spf13", "cobra", map[string]any{"archived": true}))
+	mux.HandleFunc("/repos/urfave/cli", repo(2, "urfave", "cli", map[string]any{"language": "Shell"}))
+	mux.HandleFunc("/repos/casbin/casbin", repo(3, "casbin", "casbin", map[string]any{"topics": []string{"rbac", "golang"}}))
+
+	cfg := config.Config{
+		Language:    "go",
+		PushedSince: time.Now().Add(-24 * time.Hour),
+		Hashtags:    config.Hashtags{Map: config.DefaultHashtagMap()},
+	}
+	cache := testutil.MemCache{}
+	p := githubProvider(t, mux, cfg, cache)
+	cur, err := provider.NewCurated(p, "avelino/awesome-go", "")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	c, err := provider.GetContentToPublish(ctx, cur)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:3", c.Key)
+	assert.Equal(t, "#go #authentication-oauth #rbac", c.Hashtag)
+	assert.Contains(t, cache, "curated:casbin/casbin")
+
+	// the rejected entries are skipped without looking them up again
+	c, err = cur.Next(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+	assert.Equal(t, "archived", cache["curated:spf13/cobra"])
+	assert.Equal(t, "language Shell", cache["curated:urfave/cli"])
+	assert.Equal(t, 1, readmeFetches)
+}
+
+func TestNewCurated_InvalidList(t *testing.T) {
+	_, err := provider.NewCurated(provider.Provider{}, "awesome-go", "")
+	assert.Error(t, err)
+}
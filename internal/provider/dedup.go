@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/go-github/v90/github"
+)
+
+// sharedHosts are hosts whose front page says nothing about a project, so
+// a homepage pointing there isn't used to match mirrors.
+var sharedHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"codeberg.org":  true,
+	"bitbucket.org": true,
+	"pkg.go.dev":    true,
+	"godoc.org":     true,
+	"go.dev":        true,
+}
+
+// siteKey returns the cache key for a project URL: host and path, without
+// scheme, "www.", trailing slash or ".git". It's "" when u doesn't identify
+// a project.
+func siteKey(u string) string {
+	u = strings.TrimSpace(u)
+	if u == "" {
+		return ""
+	}
+	if !strings.Contains(u, "://") {
+		u = "https://" + u
+	}
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+	path := strings.TrimSuffix(strings.TrimRight(parsed.Path, "/"), ".git")
+	if path == "" && sharedHosts[host] {
+		return ""
+	}
+	return "site:" + host + strings.ToLower(path)
+}
+
+// aliases drops the empty ones of urls.
+func aliases(urls ...string) []string {
+	var out []string
+	for _, u := range urls {
+		if u = strings.TrimSpace(u); u != "" {
+			out = append(out, u)
+		}
+	}
+	return out
+}
+
+func moduleKey(path string) string {
+	return "module:" + path
+}
+
+// duplicate returns why c is a copy of a repo that was already featured,
+// or "" when it isn't: a fork whose parent or source was featured, or a
+// mirror sharing a URL or module path with a featured repo. A URL match
+// with a repo of the same owner on the same host doesn't count, that's
+// sister projects sharing the owner's website.
+func duplicate(ctx context.Context, cc Cache, c *Content) (string, error) {
+	for _, key := range c.Network {
+		seen, err := isSeen(ctx, cc, key)
+		if err != nil {
+			return "", err
+		}
+		if seen {
+			return "fork of featured " + key, nil
+		}
+	}
+
+	for _, u := range append([]string{c.URL}, c.Aliases...) {
+		key := siteKey(u)
+		if key == "" {
+			continue
+		}
+		featured, err := cc.Get(ctx, key)
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if featured != c.URL && !sameOwner(featured, c.URL) {
+			return "mirror of featured " + featured, nil
+		}
+	}
+
+	if c.Module != nil && c.Module.Path != "" {
+		featured, err := cc.Get(ctx, moduleKey(c.Module.Path))
+		if err == nil && featured != c.URL {
+			return "module " + c.Module.Path + " featured as " + featured, nil
+		}
+		if err != nil && err != redis.Nil {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// markDuplicates records c's fork network, URLs and module path so copies
+// of it are skipped by duplicate. The URL keys hold c's URL.
+func markDuplicates(ctx context.Context, cc Cache, c *Content) error {
+	for _, key := range c.Network {
+		if err := markSeen(ctx, cc, key); err != nil {
+			return err
+		}
+	}
+
+	for _, u := range append([]string{c.URL}, c.Aliases...) {
+		if key := siteKey(u); key != "" {
+			if err := cc.Set(ctx, key, c.URL, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.Module != nil && c.Module.Path != "" {
+		return cc.Set(ctx, moduleKey(c.Module.Path), c.URL, 0)
+	}
+	return nil
+}
+
+// sameOwner reports whether two repo URLs are on the same host under the
+// same owner.
+func sameOwner(a, b string) bool {
+	owner := func(s string) string {
+		u, err := url.Parse(s)
+		if err != nil {
+			return ""
+		}
+		first, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+		if first == "" {
+			return ""
+		}
+		return strings.ToLower(u.Host + "/" + first)
+	}
+	oa := owner(a)
+	return oa != "" && oa == owner(b)
+}
+
+// forkNetwork returns the cache keys of repo's parent and source. Search
+// results only say whether a repo is a fork, so the repo is fetched for
+// them. The lookup is best effort and skipped when the API budget runs low.
+func (p Provider) forkNetwork(ctx context.Context, repo *github.Repository) []string {
+	if repo.Parent == nil && repo.Source == nil {
+		if !repo.GetFork() || p.Rates.low(rateCore) {
+			return nil
+		}
+
+		var full *github.Repository
+		err := p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+			full, resp, err = p.GitHubRepoClient.Get(ctx, repo.GetOwner().GetLogin(), repo.GetName())
+			return resp, err
+		})
+		if err != nil {
+			slog.WarnContext(ctx, "fork lookup failed", "repo", repo.GetFullName(), "err", err)
+			return nil
+		}
+		repo = full
+	}
+
+	var keys []string
+	for _, r := range []*github.Repository{repo.Parent, repo.Source} {
+		if r.GetID() == 0 {
+			continue
+		}
+		if key := repoKey(r.GetID()); !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,106 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestProvider_NextSkipsCopiesOfFeatured(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		res := searchResult(4, 1, 2, 3, 4)
+		items := res["items"].([]map[string]any)
+		items[0]["fork"] = true
+		items[1]["homepage"] = "https://www.example.org/tool/"
+		items[3]["fork"] = true
+		items[3]["homepage"] = "https://blog.example"
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	// search results don't carry parent and source
+	mux.HandleFunc("/repos/owner/repo1", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id": 1, "fork": true,
+			"parent": map[string]any{"id": 6},
+			"source": map[string]any{"id": 7},
+		})
+	})
+	mux.HandleFunc("/repos/owner/repo3/contents/go.mod", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte("module example.org/lib\n")),
+		})
+	})
+	mux.HandleFunc("/repos/owner/repo4", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id": 4, "fork": true,
+			"parent": map[string]any{"id": 8},
+			"source": map[string]any{"id": 8},
+		})
+	})
+
+	cache := testutil.MemCache{
+		"repo:7":                 "true",
+		"site:example.org/tool":  "https://codeberg.org/other/tool",
+		"module:example.org/lib": "https://github.com/other/lib",
+		// the owner's blog is the homepage of their other repos too
+		"site:blog.example": "https://github.com/owner/other",
+	}
+	cfg := config.Config{PushedSince: time.Now().Add(-24 * time.Hour), PickTop: true}
+	p := githubProvider(t, mux, cfg, cache)
+	p.Scorer = func(repo *github.Repository, _ time.Time) provider.Score {
+		// rank in search order so every candidate is checked
+		return provider.Score{Total: -float64(repo.GetID()), Parts: map[string]float64{}}
+	}
+
+	ctx := context.Background()
+	c, err := p.Next(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:4", c.Key)
+	assert.Equal(t, []string{"repo:8"}, c.Network)
+
+	require.NoError(t, p.MarkSeen(ctx, c))
+	assert.Contains(t, cache, "repo:8", "the fork network is marked seen")
+	assert.Equal(t, "https://github.com/owner/repo4", cache["site:github.com/owner/repo4"])
+	assert.Equal(t, "https://github.com/owner/repo4", cache["site:blog.example"])
+}
+
+func TestGitea_NextSkipsMirrorOfFeatured(t *testing.T) {
+	now := time.Now()
+	mirror := giteaRepo(1, "tool", "Go", now)
+	mirror["original_url"] = "https://github.com/owner/tool.git"
+	fork := giteaRepo(2, "lib", "Go", now)
+	fork["parent"] = map[string]any{"id": 9}
+	srv := giteaServer(t, []map[string]any{mirror, fork, giteaRepo(3, "app", "Go", now)})
+
+	cache := testutil.MemCache{
+		"site:github.com/owner/tool": "https://github.com/owner/tool",
+	}
+	g, err := provider.NewGitea(srv.URL, "secret", config.Config{Language: "go", PushedSince: now.Add(-time.Hour)}, cache)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	c, err := g.Next(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "gitea:"+srv.Listener.Addr().String()+":2", c.Key)
+
+	require.NoError(t, g.MarkSeen(ctx, c))
+	c, err = g.Next(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "gitea:"+srv.Listener.Addr().String()+":3", c.Key)
+	assert.Contains(t, cache, "gitea:"+srv.Listener.Addr().String()+":9")
+}
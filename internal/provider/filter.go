@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/policy"
+	"github.com/till/golangoss-bluesky/internal/spam"
+)
+
+// reject returns why repo doesn't pass the filters the search query can't
+// express, or "" when it's a valid candidate.
+func (p Provider) reject(repo *github.Repository) string {
+	if denied, rule := p.Config.Policy.Denied(policyRepo(repo)); denied {
+		return "policy: " + rule
+	}
+	// a missing description is checked once the cheaper filters passed, as
+	// the README may stand in for it
+	q := p.Config.Quality
+	q.RequireDescription = false
+	if reason := qualityReject(q, repoFacts{
+		Fork:        repo.GetFork(),
+		Template:    repo.GetIsTemplate(),
+		Mirror:      repo.GetMirrorURL() != "",
+		Stars:       repo.GetStargazersCount(),
+		SizeKB:      repo.GetSize(),
+		Description: repo.GetDescription(),
+	}); reason != "" {
+		return reason
+	}
+	return licenseReject(p.Config.License, repo.GetLicense().GetSPDXID())
+}
+
+// repoFacts is what the quality filters look at, taken from any forge's
+// repo object.
+type repoFacts struct {
+	Fork        bool
+	Template    bool
+	Mirror      bool
+	Stars       int
+	SizeKB      int // negative when the forge doesn't report it
+	Description string
+}
+
+// qualityReject returns why a repo doesn't pass the quality filters, or ""
+// when it does.
+func qualityReject(q config.Quality, f repoFacts) string {
+	switch {
+	case q.ExcludeForks && f.Fork:
+		return "fork"
+	case q.ExcludeTemplates && f.Template:
+		return "template"
+	case q.ExcludeMirrors && f.Mirror:
+		return "mirror"
+	case q.MinStars > 0 && f.Stars < q.MinStars:
+		return fmt.Sprintf("%d stars, want at least %d", f.Stars, q.MinStars)
+	case q.MaxStars > 0 && f.Stars > q.MaxStars:
+		return fmt.Sprintf("%d stars, want at most %d", f.Stars, q.MaxStars)
+	case q.MinSizeKB > 0 && f.SizeKB >= 0 && f.SizeKB < q.MinSizeKB:
+		return fmt.Sprintf("size %dKB, want at least %dKB", f.SizeKB, q.MinSizeKB)
+	case q.RequireDescription && strings.TrimSpace(f.Description) == "":
+		return "no description"
+	}
+	return ""
+}
+
+// licenseReject returns why a repo licensed under spdx doesn't pass the
+// policy, or "" when it does.
+func licenseReject(l config.LicensePolicy, spdx string) string {
+	if l.Allows(spdx) {
+		return ""
+	}
+	if spdx == "" || spdx == "NOASSERTION" {
+		return "no license"
+	}
+	return fmt.Sprintf("license %s not allowed", spdx)
+}
+
+// spdxID returns the canonical spelling of a license ID reported in another
+// case, e.g. GitLab's "apache-2.0", when it's one of the OSI licenses.
+func spdxID(id string) string {
+	for _, known := range config.OSILicenses {
+		if strings.EqualFold(known, id) {
+			return known
+		}
+	}
+	return id
+}
+
+// searchReject applies the filters of the search query to a repo that was
+// looked up directly, e.g. from a list or a submission. It returns "" when
+// repo passes.
+func (p Provider) searchReject(repo *github.Repository) string {
+	cfg := p.Config
+	switch {
+	case repo.GetArchived() && !cfg.Archived:
+		return "archived"
+	case cfg.Language != "" && !strings.EqualFold(repo.GetLanguage(), cfg.Language):
+		return "language " + repo.GetLanguage()
+	case cfg.Topic != "" && !slices.ContainsFunc(repo.Topics, func(t string) bool { return strings.EqualFold(t, cfg.Topic) }):
+		return "topic missing"
+	case repo.GetPushedAt().Before(cfg.PushedSince):
+		return "inactive"
+	}
+	return ""
+}
+
+func policyRepo(repo *github.Repository) policy.Repo {
+	return policy.Repo{
+		Owner:       repo.GetOwner().GetLogin(),
+		Name:        repo.GetName(),
+		Description: repo.GetDescription(),
+		Topics:      repo.Topics,
+	}
+}
+
+// deniedContent applies the policy and the spam classifier to candidates of
+// the other forges, which are checked after they've been converted to
+// Content.
+func deniedContent(ctx context.Context, cfg config.Config, c *Content) bool {
+	pol := policy.Repo{Owner: c.Author.Login, Name: c.Title, Description: c.Description, Topics: c.Topics}
+	sp := spam.Repo{Name: c.Title, Description: c.Description, Topics: c.Topics}
+
+	reason := ""
+	if denied, rule := cfg.Policy.Denied(pol); denied {
+		reason = "policy: " + rule
+	} else if v := cfg.Spam.Classify(sp, time.Now()); v.Spam {
+		reason = "spam: " + v.Reason()
+	}
+
+	if reason != "" {
+		slog.DebugContext(ctx, "rejected candidate", "key", c.Key, "reason", reason)
+	}
+	return reason != ""
+}
@@ -0,0 +1,360 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/till/golangoss-bluesky/internal/config"
+)
+
+const (
+	// giteaPageSize is the max page size Gitea allows by default.
+	giteaPageSize = 50
+	// giteaMaxPages caps how far we walk the search per cycle. The search API
+	// can't filter by language, so we page through recently updated repos and
+	// filter on our side.
+	giteaMaxPages = 5
+)
+
+var _ Source = Gitea{}
+
+var errGiteaNotFound = errors.New("not found")
+
+// Gitea searches a Gitea or Forgejo instance (e.g. Codeberg) for repos.
+// Cache keys are namespaced by host, "gitea:<host>:<id>", so they can't
+// collide with GitHub's "repo:<id>".
+type Gitea struct {
+	Config      config.Config
+	CacheClient Cache
+
+	baseURL    string
+	host       string
+	token      string
+	httpClient *http.Client
+}
+
+// giteaRepo is the part of Gitea's repository object we read.
+type giteaRepo struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	FullName    string    `json:"full_name"`
+	Description string    `json:"description"`
+	HTMLURL     string    `json:"html_url"`
+	Stars       int       `json:"stars_count"`
+	Archived    bool      `json:"archived"`
+	Language    string    `json:"language"`
+	Topics      []string  `json:"topics"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	Owner       struct {
+		Login string `json:"login"`
+	} `json:"owner"`
+	Fork     bool `json:"fork"`
+	Template bool `json:"template"`
+	Mirror   bool `json:"mirror"`
+	Size     int  `json:"size"` // KB
+	// Licenses are the SPDX IDs Gitea detected, missing on Forgejo and
+	// Gitea before 1.23. The license file is read instead then.
+	Licenses    []string `json:"licenses"`
+	Website     string   `json:"website"`
+	OriginalURL string   `json:"original_url"` // upstream of a mirror
+	Parent      *struct {
+		ID int64 `json:"id"`
+	} `json:"parent"`
+}
+
+type giteaSearchResult struct {
+	OK   bool        `json:"ok"`
+	Data []giteaRepo `json:"data"`
+}
+
+// giteaEntry is a file or directory listed by the contents API.
+type giteaEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// NewGitea creates a source for the instance at baseURL, e.g.
+// "https://codeberg.org". The token is optional; anonymous search works on
+// public instances but has a lower rate limit.
+func NewGitea(baseURL, token string, cfg config.Config, cacheClient Cache) (Gitea, error) {
+	slog.Info("New Gitea Provider", "url", baseURL)
+	g := Gitea{Config: cfg, CacheClient: cacheClient, token: token, httpClient: http.DefaultClient}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return g, fmt.Errorf("gitea url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return g, fmt.Errorf("gitea url %q: scheme and host required", baseURL)
+	}
+	g.baseURL = strings.TrimSuffix(u.String(), "/")
+	g.host = u.Host
+
+	return g, nil
+}
+
+// Next walks the recently updated repos and returns the first one that
+// matches the config and isn't cached. It resumes on the page the previous
+// cycle stopped at and starts over from the most recent repos once it
+// reaches PushedSince or the end of the listing. Returns (nil, nil) when
+// nothing qualifies within giteaMaxPages.
+func (g Gitea) Next(ctx context.Context) (*Content, error) {
+	cur := loadPageCursor(ctx, g.CacheClient, g.cursorKey())
+
+	for range giteaMaxPages {
+		repos, err := g.search(ctx, cur.Page)
+		if err != nil {
+			return nil, err
+		}
+
+		c, end, err := g.pick(ctx, repos)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			savePageCursor(ctx, g.CacheClient, g.cursorKey(), cur)
+			return c, nil
+		}
+		if end || len(repos) < giteaPageSize {
+			slog.DebugContext(ctx, "gitea search exhausted, starting over", "host", g.host)
+			cur = pageCursor{Page: 1}
+			break
+		}
+		cur.Page++
+	}
+
+	savePageCursor(ctx, g.CacheClient, g.cursorKey(), cur)
+	return nil, nil
+}
+
+// pick returns the first repo of a page that qualifies and isn't cached.
+// end is set when the page reaches repos updated before PushedSince.
+func (g Gitea) pick(ctx context.Context, repos []giteaRepo) (c *Content, end bool, err error) {
+	for _, repo := range repos {
+		// sorted by last update, so everything after this is older
+		if repo.UpdatedAt.Before(g.Config.PushedSince) {
+			return nil, true, nil
+		}
+		if !g.matches(repo) {
+			continue
+		}
+		c := g.toContent(repo)
+		reason := qualityReject(g.Config.Quality, g.facts(repo))
+		if reason == "" && c.License == "" {
+			// the forge didn't detect a license, the license file is
+			// read for the repos that are left
+			if c.License, err = g.licenseFile(ctx, repo.FullName); err != nil {
+				return nil, false, err
+			}
+		}
+		if reason == "" {
+			reason = licenseReject(g.Config.License, c.License)
+		}
+		if reason != "" {
+			slog.DebugContext(ctx, "rejected candidate", "key", c.Key, "reason", reason)
+			continue
+		}
+		if deniedContent(ctx, g.Config, c) {
+			continue
+		}
+
+		seen, err := isSeen(ctx, g.CacheClient, c.Key)
+		if err != nil {
+			return nil, false, err
+		}
+		if seen {
+			continue
+		}
+		reason, err = duplicate(ctx, g.CacheClient, c)
+		if err != nil {
+			return nil, false, err
+		}
+		if reason != "" {
+			slog.DebugContext(ctx, "rejected candidate", "key", c.Key, "reason", reason)
+			continue
+		}
+		return c, false, nil
+	}
+	return nil, false, nil
+}
+
+// Enrich is a no-op: Gitea has no social account links to look up.
+func (g Gitea) Enrich(_ context.Context, _ *Content) error {
+	return nil
+}
+
+// MarkSeen caches the repo's key along with its parent, URLs and website.
+func (g Gitea) MarkSeen(ctx context.Context, c *Content) error {
+	if err := markSeen(ctx, g.CacheClient, c.Key); err != nil {
+		return err
+	}
+	return markDuplicates(ctx, g.CacheClient, c)
+}
+
+func (g Gitea) search(ctx context.Context, page int) ([]giteaRepo, error) {
+	q := url.Values{}
+	q.Set("sort", "updated")
+	q.Set("order", "desc")
+	q.Set("limit", strconv.Itoa(giteaPageSize))
+	q.Set("page", strconv.Itoa(page))
+	if !g.Config.Archived {
+		q.Set("archived", "false")
+	}
+
+	body, err := g.get(ctx, "/api/v1/repos/search?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("gitea search: %w", err)
+	}
+	var res giteaSearchResult
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("gitea search: %w", err)
+	}
+	return res.Data, nil
+}
+
+// licenseFile returns the SPDX ID of the license in the repo's license
+// file, "" when there's none or it's not one we recognize. It's only read
+// when the policy or the post needs it.
+func (g Gitea) licenseFile(ctx context.Context, fullName string) (string, error) {
+	l := g.Config.License
+	if !l.Required && len(l.Allowed) == 0 && !l.ShowInPost {
+		return "", nil
+	}
+
+	body, err := g.get(ctx, "/api/v1/repos/"+fullName+"/contents")
+	if errors.Is(err, errGiteaNotFound) {
+		// an empty repo has no contents
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("gitea license of %s: %w", fullName, err)
+	}
+	var entries []giteaEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return "", fmt.Errorf("gitea license of %s: %w", fullName, err)
+	}
+
+	i := slices.IndexFunc(entries, func(e giteaEntry) bool {
+		return e.Type == "file" && isLicenseFile(e.Name)
+	})
+	if i < 0 {
+		return "", nil
+	}
+	text, err := g.get(ctx, "/api/v1/repos/"+fullName+"/raw/"+url.PathEscape(entries[i].Name))
+	if err != nil {
+		return "", fmt.Errorf("gitea license of %s: %w", fullName, err)
+	}
+	return licenseFromText(string(text)), nil
+}
+
+// get returns the body of the API response at path.
+func (g Gitea) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if g.token != "" {
+		req.Header.Set("Authorization", "token "+g.token)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, errGiteaNotFound
+	default:
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+// matches applies the filters the search API can't express.
+func (g Gitea) matches(repo giteaRepo) bool {
+	if repo.Archived && !g.Config.Archived {
+		return false
+	}
+	if g.Config.Language != "" && !strings.EqualFold(repo.Language, g.Config.Language) {
+		return false
+	}
+	if g.Config.Topic != "" && !slices.ContainsFunc(repo.Topics, func(t string) bool {
+		return strings.EqualFold(t, g.Config.Topic)
+	}) {
+		return false
+	}
+	return true
+}
+
+// facts returns what the quality filters look at.
+func (g Gitea) facts(repo giteaRepo) repoFacts {
+	return repoFacts{
+		Fork:        repo.Fork,
+		Template:    repo.Template,
+		Mirror:      repo.Mirror,
+		Stars:       repo.Stars,
+		SizeKB:      repo.Size,
+		Description: repo.Description,
+	}
+}
+
+// license returns the repo's license that the policy allows, or the first
+// one when none is.
+func (g Gitea) license(repo giteaRepo) string {
+	for _, id := range repo.Licenses {
+		if g.Config.License.Allows(id) {
+			return spdxID(id)
+		}
+	}
+	if len(repo.Licenses) > 0 {
+		return spdxID(repo.Licenses[0])
+	}
+	return ""
+}
+
+func (g Gitea) key(id int64) string {
+	return fmt.Sprintf("gitea:%s:%d", g.host, id)
+}
+
+func (g Gitea) cursorKey() string {
+	return "cursor:gitea:" + g.host
+}
+
+func (g Gitea) toContent(repo giteaRepo) *Content {
+	c := &Content{
+		Key:         g.key(repo.ID),
+		Title:       repo.Name,
+		FullName:    repo.FullName,
+		Description: repo.Description,
+		URL:         repo.HTMLURL,
+		Stars:       repo.Stars,
+		License:     g.license(repo),
+		Topics:      repo.Topics,
+		Hashtag:     hashtags(g.Config, repo.Topics),
+		Aliases:     aliases(repo.Website, repo.OriginalURL),
+	}
+	if repo.Parent != nil && repo.Parent.ID != 0 {
+		c.Network = []string{g.key(repo.Parent.ID)}
+	}
+	if repo.Owner.Login != "" {
+		c.Author = Author{
+			Login:      repo.Owner.Login,
+			ProfileURL: g.baseURL + "/" + repo.Owner.Login,
+		}
+	}
+	return c
+}
@@ -0,0 +1,336 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func giteaServer(t *testing.T, repos []map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/search" {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, "updated", r.URL.Query().Get("sort"))
+		assert.Equal(t, "false", r.URL.Query().Get("archived"))
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+
+		data := repos
+		if r.URL.Query().Get("page") != "1" {
+			data = nil
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "data": data})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func giteaRepo(id int, name, lang string, updated time.Time) map[string]any {
+	return map[string]any{
+		"id":          id,
+		"name":        name,
+		"description": name + " does things",
+		"html_url":    "https://codeberg.org/owner/" + name,
+		"stars_count": 7,
+		"language":    lang,
+		"updated_at":  updated.Format(time.RFC3339),
+		"owner":       map[string]any{"login": "owner"},
+	}
+}
+
+func TestGitea_Next(t *testing.T) {
+	now := time.Now().UTC()
+	srv := giteaServer(t, []map[string]any{
+		giteaRepo(1, "rusty", "Rust", now),
+		giteaRepo(2, "seen", "Go", now),
+		giteaRepo(3, "fresh", "Go", now.Add(-time.Hour)),
+		giteaRepo(4, "stale", "Go", now.Add(-48*time.Hour)),
+	})
+
+	cfg := config.Config{Language: "go", PushedSince: now.Add(-24 * time.Hour)}
+	host := strings.TrimPrefix(srv.URL, "http://")
+	cache := testutil.MemCache{"gitea:" + host + ":2": true}
+
+	g, err := provider.NewGitea(srv.URL, "secret", cfg, cache)
+	require.NoError(t, err)
+
+	c, err := g.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+
+	assert.Equal(t, "gitea:"+host+":3", c.Key)
+	assert.Equal(t, "fresh", c.Title)
+	assert.Equal(t, "fresh does things", c.Description)
+	assert.Equal(t, "https://codeberg.org/owner/fresh", c.URL)
+	assert.Equal(t, 7, c.Stars)
+	assert.Equal(t, "#go", c.Hashtag)
+	assert.Equal(t, "owner", c.Author.Login)
+	assert.Equal(t, srv.URL+"/owner", c.Author.ProfileURL)
+
+	require.NoError(t, g.MarkSeen(context.Background(), c))
+
+	// the only other Go repo is older than PushedSince
+	c, err = g.Next(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestGitea_NextResumesFromCursor(t *testing.T) {
+	now := time.Now().UTC()
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		// six full pages of other languages, then a single Go repo
+		var data []map[string]any
+		switch n, _ := strconv.Atoi(page); {
+		case n <= 6:
+			for i := range 50 {
+				data = append(data, giteaRepo(n*100+i, "rusty", "Rust", now))
+			}
+		case n == 7:
+			data = append(data, giteaRepo(700, "found", "Go", now))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "data": data})
+	}))
+	t.Cleanup(srv.Close)
+
+	g, err := provider.NewGitea(srv.URL, "", config.Config{Language: "go", PushedSince: now.Add(-time.Hour)}, testutil.MemCache{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	c, err := g.Next(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, pages)
+
+	pages = nil
+	c, err = g.Next(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "found", c.Title)
+	assert.Equal(t, []string{"6", "7"}, pages, "the second cycle picks up after the first")
+	require.NoError(t, g.MarkSeen(ctx, c))
+
+	// the last page is used up, so the listing starts over
+	pages = nil
+	c, err = g.Next(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+	assert.Equal(t, []string{"7"}, pages)
+
+	pages = nil
+	_, err = g.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", pages[0])
+}
+
+func TestGitea_NextAppliesQualityAndLicense(t *testing.T) {
+	now := time.Now().UTC()
+	repo := func(id int, edit func(map[string]any)) map[string]any {
+		r := giteaRepo(id, fmt.Sprintf("repo%d", id), "Go", now)
+		r["size"] = 100
+		r["licenses"] = []string{"MIT"}
+		edit(r)
+		return r
+	}
+	srv := giteaServer(t, []map[string]any{
+		repo(1, func(r map[string]any) { r["fork"] = true }),
+		repo(2, func(r map[string]any) { r["mirror"] = true }),
+		repo(3, func(r map[string]any) { r["size"] = 0 }),
+		repo(4, func(r map[string]any) { r["description"] = "" }),
+		repo(5, func(r map[string]any) { delete(r, "licenses") }),
+		repo(6, func(r map[string]any) { r["licenses"] = []string{"BUSL-1.1"} }),
+		repo(7, func(r map[string]any) { r["licenses"] = []string{"BUSL-1.1", "mit"} }),
+	})
+
+	cfg := config.Config{
+		Language:    "go",
+		PushedSince: now.Add(-time.Hour),
+		License:     config.LicensePolicy{Required: true, Allowed: config.OSILicenses},
+		Quality: config.Quality{
+			MinSizeKB:          1,
+			ExcludeForks:       true,
+			ExcludeMirrors:     true,
+			RequireDescription: true,
+		},
+	}
+	g, err := provider.NewGitea(srv.URL, "secret", cfg, testutil.MemCache{})
+	require.NoError(t, err)
+
+	c, err := g.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo7", c.Title)
+	assert.Equal(t, "MIT", c.License, "the allowed license is reported in its SPDX spelling")
+}
+
+func TestGitea_NextServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	g, err := provider.NewGitea(srv.URL, "", config.Config{}, testutil.MemCache{})
+	require.NoError(t, err)
+
+	_, err = g.Next(context.Background())
+	require.Error(t, err)
+}
+
+func TestNewGitea_InvalidURL(t *testing.T) {
+	_, err := provider.NewGitea("codeberg.org", "", config.Config{}, testutil.MemCache{})
+	require.Error(t, err)
+}
+
+// forgejoServer serves repos the way Forgejo does, without detected
+// licenses, and files[fullName] as each repo's root files.
+func forgejoServer(t *testing.T, repos []map[string]any, files map[string]map[string]string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v1/repos/")
+		switch {
+		case path == "search":
+			data := repos
+			if r.URL.Query().Get("page") != "1" {
+				data = nil
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "data": data})
+		case strings.HasSuffix(path, "/contents"):
+			entries := []map[string]string{{"name": "go.mod", "type": "file"}, {"name": "cmd", "type": "dir"}}
+			for name := range files[strings.TrimSuffix(path, "/contents")] {
+				entries = append(entries, map[string]string{"name": name, "type": "file"})
+			}
+			_ = json.NewEncoder(w).Encode(entries)
+		case strings.Contains(path, "/raw/"):
+			fetched = append(fetched, path)
+			repo, name, _ := strings.Cut(path, "/raw/")
+			text, ok := files[repo][name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(text))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &fetched
+}
+
+func forgejoRepo(id int, name string) map[string]any {
+	r := giteaRepo(id, name, "Go", time.Now().UTC())
+	r["full_name"] = "owner/" + name
+	return r
+}
+
+const mitText = `MIT License
+
+Copyright (c) 2026 owner
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction.`
+
+func TestGitea_LicenseFromFile(t *testing.T) {
+	gitea := forgejoRepo(4, "detected")
+	gitea["licenses"] = []string{"Apache-2.0"}
+
+	srv, fetched := forgejoServer(t, []map[string]any{
+		forgejoRepo(1, "unlicensed"),
+		forgejoRepo(2, "source-available"),
+		forgejoRepo(3, "mit"),
+		gitea,
+	}, map[string]map[string]string{
+		"owner/source-available": {"LICENSE": "Business Source License 1.1\n\nLicensor: owner"},
+		"owner/mit":              {"LICENSE.md": mitText},
+		"owner/detected":         {"LICENSE": "not read, the forge detected it"},
+	})
+
+	// the bot's defaults
+	cfg := config.Config{
+		Language:    "go",
+		PushedSince: time.Now().Add(-time.Hour),
+		License:     config.LicensePolicy{Required: true, Allowed: config.OSILicenses},
+	}
+	g, err := provider.NewGitea(srv.URL, "", cfg, testutil.MemCache{})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	c, err := g.Next(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "mit", c.Title)
+	assert.Equal(t, "MIT", c.License)
+	require.NoError(t, g.MarkSeen(ctx, c))
+
+	c, err = g.Next(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "detected", c.Title)
+	assert.Equal(t, "Apache-2.0", c.License)
+
+	assert.NotContains(t, *fetched, "owner/detected/raw/LICENSE")
+}
+
+func TestGitea_LicenseTexts(t *testing.T) {
+	testCases := []struct {
+		name string
+		file string
+		text string
+		want string
+	}{
+		{"mit", "LICENSE", mitText, "MIT"},
+		{"apache", "LICENSE.txt", "\n                                 Apache License\n                           Version 2.0, January 2004\n", "Apache-2.0"},
+		{"gpl-3", "COPYING", "                    GNU GENERAL PUBLIC LICENSE\n                       Version 3, 29 June 2007\n", "GPL-3.0"},
+		{"gpl-2 mentioning the lgpl", "COPYING", "GNU GENERAL PUBLIC LICENSE\nVersion 2, June 1991\n\n(Some other Free Software Foundation software is covered by\nthe GNU Lesser General Public License instead.)", "GPL-2.0"},
+		{"lgpl-3", "COPYING.LESSER", "GNU LESSER GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n\nThis version of the GNU Lesser General Public License incorporates\nthe terms and conditions of version 3 of the GNU General Public\nLicense", "LGPL-3.0"},
+		{"agpl-3", "LICENSE", "GNU AFFERO GENERAL PUBLIC LICENSE\nVersion 3, 19 November 2007", "AGPL-3.0"},
+		{"mpl", "LICENSE", "Mozilla Public License Version 2.0\n==================================", "MPL-2.0"},
+		{"bsd-3", "LICENSE", "Copyright (c) owner\n\nRedistribution and use in source and binary forms, with or without\nmodification, are permitted provided that the following conditions are met:\n\n3. Neither the name of the copyright holder nor the names of its\ncontributors may be used", "BSD-3-Clause"},
+		{"bsd-2", "LICENSE", "Copyright (c) owner\n\nRedistribution and use in source and binary forms, with or without\nmodification, are permitted", "BSD-2-Clause"},
+		{"isc", "LICENSE", "Permission to use, copy, modify, and/or distribute this software for any\npurpose with or without fee is hereby granted, provided that the above\ncopyright notice", "ISC"},
+		{"0bsd", "LICENSE", "Permission to use, copy, modify, and/or distribute this software for any\npurpose with or without fee is hereby granted.\n\nTHE SOFTWARE IS PROVIDED", "0BSD"},
+		{"unlicense", "LICENSE", "This is free and unencumbered software released into the public domain.", "Unlicense"},
+		{"not a license file", "NOTICE", mitText, ""},
+		{"spdx tag", "LICENCE", "SPDX-License-Identifier: mpl-2.0\n", "MPL-2.0"},
+		{"unrecognized", "LICENSE", "All rights reserved.", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv, _ := forgejoServer(t, []map[string]any{forgejoRepo(1, "repo")},
+				map[string]map[string]string{"owner/repo": {tc.file: tc.text}})
+
+			cfg := config.Config{
+				Language:    "go",
+				PushedSince: time.Now().Add(-time.Hour),
+				License:     config.LicensePolicy{ShowInPost: true},
+			}
+			g, err := provider.NewGitea(srv.URL, "", cfg, testutil.MemCache{})
+			require.NoError(t, err)
+
+			c, err := g.Next(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			assert.Equal(t, tc.want, c.License)
+		})
+	}
+}
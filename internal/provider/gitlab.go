@@ -0,0 +1,295 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/till/golangoss-bluesky/internal/config"
+)
+
+const (
+	// gitlabPageSize is the max page size of GitLab's REST API.
+	gitlabPageSize = 100
+	// gitlabMaxPages caps the listing calls one Next makes.
+	gitlabMaxPages = 3
+)
+
+var _ Source = GitLab{}
+
+// GitLab lists public projects on a GitLab instance with the configured
+// language detected. Cache keys are "gitlab:<host>:<id>".
+type GitLab struct {
+	Config      config.Config
+	CacheClient Cache
+
+	baseURL    string
+	host       string
+	token      string
+	httpClient *http.Client
+}
+
+// gitlabProject is the part of GitLab's project object we read.
+type gitlabProject struct {
+	ID             int64     `json:"id"`
+	Name           string    `json:"name"`
+	PathWithNS     string    `json:"path_with_namespace"`
+	Description    string    `json:"description"`
+	WebURL         string    `json:"web_url"`
+	Stars          int       `json:"star_count"`
+	Archived       bool      `json:"archived"`
+	Mirror         bool      `json:"mirror"`
+	Topics         []string  `json:"topics"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	Namespace      struct {
+		// FullPath includes the parent groups, e.g. "grp/sub".
+		FullPath string `json:"full_path"`
+		WebURL   string `json:"web_url"`
+	} `json:"namespace"`
+	ForkedFrom *struct {
+		ID int64 `json:"id"`
+	} `json:"forked_from_project"`
+}
+
+// NewGitLab creates a source for the instance at baseURL, e.g.
+// "https://gitlab.com". The token is optional.
+func NewGitLab(baseURL, token string, cfg config.Config, cacheClient Cache) (GitLab, error) {
+	slog.Info("New GitLab Provider", "url", baseURL)
+	g := GitLab{Config: cfg, CacheClient: cacheClient, token: token, httpClient: http.DefaultClient}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return g, fmt.Errorf("gitlab url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return g, fmt.Errorf("gitlab url %q: scheme and host required", baseURL)
+	}
+	g.baseURL = strings.TrimSuffix(u.String(), "/")
+	g.host = u.Host
+
+	return g, nil
+}
+
+// Next returns the most recently active project that isn't cached. It
+// resumes on the page the previous cycle stopped at and starts over from
+// the most recent projects once the listing ends. Returns (nil, nil) when
+// nothing qualifies within gitlabMaxPages.
+func (g GitLab) Next(ctx context.Context) (*Content, error) {
+	cur := loadPageCursor(ctx, g.CacheClient, g.cursorKey())
+
+	for range gitlabMaxPages {
+		projects, next, err := g.list(ctx, cur.Page)
+		if err != nil {
+			return nil, err
+		}
+
+		c, err := g.pick(ctx, projects)
+		if err != nil {
+			return nil, err
+		}
+		if c != nil {
+			savePageCursor(ctx, g.CacheClient, g.cursorKey(), cur)
+			return c, nil
+		}
+		if next == 0 {
+			slog.DebugContext(ctx, "gitlab listing exhausted, starting over", "host", g.host)
+			cur = pageCursor{Page: 1}
+			break
+		}
+		cur.Page = next
+	}
+
+	savePageCursor(ctx, g.CacheClient, g.cursorKey(), cur)
+	return nil, nil
+}
+
+// pick returns the first project of a page that qualifies and isn't cached.
+func (g GitLab) pick(ctx context.Context, projects []gitlabProject) (*Content, error) {
+	for _, p := range projects {
+		// GitLab filters these already, double-check in case an instance ignores the params
+		if p.Archived && !g.Config.Archived {
+			continue
+		}
+		if p.LastActivityAt.Before(g.Config.PushedSince) {
+			continue
+		}
+
+		c := g.toContent(p)
+		if deniedContent(ctx, g.Config, c) {
+			continue
+		}
+
+		seen, err := isSeen(ctx, g.CacheClient, c.Key)
+		if err != nil {
+			return nil, err
+		}
+		if seen {
+			continue
+		}
+		reason, err := duplicate(ctx, g.CacheClient, c)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			reason = qualityReject(g.Config.Quality, repoFacts{
+				Fork:        p.ForkedFrom != nil,
+				Mirror:      p.Mirror,
+				Stars:       p.Stars,
+				SizeKB:      -1, // only reported to project members
+				Description: p.Description,
+			})
+		}
+		if reason == "" {
+			// the listing doesn't include the license, it's looked up
+			// for the projects that are left
+			if c.License, err = g.license(ctx, p.ID); err != nil {
+				return nil, err
+			}
+			reason = licenseReject(g.Config.License, c.License)
+		}
+		if reason != "" {
+			slog.DebugContext(ctx, "rejected candidate", "key", c.Key, "reason", reason)
+			continue
+		}
+		return c, nil
+	}
+	return nil, nil
+}
+
+// Enrich is a no-op: the namespace owner is already part of the listing.
+func (g GitLab) Enrich(_ context.Context, _ *Content) error {
+	return nil
+}
+
+// MarkSeen caches the project's key along with the project it was forked
+// from and its URL.
+func (g GitLab) MarkSeen(ctx context.Context, c *Content) error {
+	if err := markSeen(ctx, g.CacheClient, c.Key); err != nil {
+		return err
+	}
+	return markDuplicates(ctx, g.CacheClient, c)
+}
+
+// list returns a page of projects and the number of the next one, 0 on the
+// last page.
+func (g GitLab) list(ctx context.Context, page int) ([]gitlabProject, int, error) {
+	q := url.Values{}
+	q.Set("visibility", "public")
+	q.Set("order_by", "last_activity_at")
+	q.Set("sort", "desc")
+	q.Set("per_page", strconv.Itoa(gitlabPageSize))
+	q.Set("page", strconv.Itoa(page))
+	q.Set("last_activity_after", g.Config.PushedSince.UTC().Format(time.RFC3339))
+	if !g.Config.Archived {
+		q.Set("archived", "false")
+	}
+	if g.Config.Language != "" {
+		q.Set("with_programming_language", g.Config.Language)
+	}
+	if g.Config.Topic != "" {
+		q.Set("topic", g.Config.Topic)
+	}
+
+	var projects []gitlabProject
+	header, err := g.get(ctx, "/api/v4/projects?"+q.Encode(), &projects)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gitlab projects: %w", err)
+	}
+
+	// X-Next-Page is empty on the last page; large listings may leave it
+	// out altogether, then a full page means there's more
+	next := 0
+	if v := header.Values("X-Next-Page"); len(v) > 0 {
+		next, _ = strconv.Atoi(v[0])
+	} else if len(projects) == gitlabPageSize {
+		next = page + 1
+	}
+	return projects, next, nil
+}
+
+// get decodes the JSON response to the API path into out and returns the
+// response's header.
+func (g GitLab) get(ctx context.Context, path string, out any) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return nil, err
+	}
+	return resp.Header, nil
+}
+
+// license returns the SPDX ID of the project's license, "" when GitLab
+// didn't recognize one. It's only looked up when the policy or the post
+// needs it.
+func (g GitLab) license(ctx context.Context, id int64) (string, error) {
+	l := g.Config.License
+	if !l.Required && len(l.Allowed) == 0 && !l.ShowInPost {
+		return "", nil
+	}
+
+	var project struct {
+		License *struct {
+			Key string `json:"key"`
+		} `json:"license"`
+	}
+	if _, err := g.get(ctx, fmt.Sprintf("/api/v4/projects/%d?license=true", id), &project); err != nil {
+		return "", fmt.Errorf("gitlab license of %d: %w", id, err)
+	}
+	if project.License == nil || project.License.Key == "other" {
+		return "", nil
+	}
+	return spdxID(project.License.Key), nil
+}
+
+func (g GitLab) key(id int64) string {
+	return fmt.Sprintf("gitlab:%s:%d", g.host, id)
+}
+
+func (g GitLab) cursorKey() string {
+	return "cursor:gitlab:" + g.host
+}
+
+func (g GitLab) toContent(p gitlabProject) *Content {
+	c := &Content{
+		Key:         g.key(p.ID),
+		Title:       p.Name,
+		FullName:    p.PathWithNS,
+		Description: p.Description,
+		URL:         p.WebURL,
+		Stars:       p.Stars,
+		Topics:      p.Topics,
+		Hashtag:     hashtags(g.Config, p.Topics),
+	}
+	if p.ForkedFrom != nil && p.ForkedFrom.ID != 0 {
+		c.Network = []string{g.key(p.ForkedFrom.ID)}
+	}
+	if p.Namespace.FullPath != "" {
+		c.Author = Author{
+			Login:      p.Namespace.FullPath,
+			ProfileURL: p.Namespace.WebURL,
+		}
+	}
+	return c
+}
@@ -0,0 +1,244 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestGitLab_Next(t *testing.T) {
+	now := time.Now().UTC()
+	since := now.Add(-24 * time.Hour)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "/api/v4/projects", r.URL.Path)
+		assert.Equal(t, "go", q.Get("with_programming_language"))
+		assert.Equal(t, "false", q.Get("archived"))
+		assert.Equal(t, since.Format(time.RFC3339), q.Get("last_activity_after"))
+
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{
+				"id": 10, "name": "seen", "web_url": "https://gitlab.com/grp/seen",
+				"last_activity_at": now.Format(time.RFC3339),
+			},
+			{
+				"id": 11, "name": "tool", "description": "a tool", "web_url": "https://gitlab.com/grp/tool",
+				"star_count": 3, "last_activity_at": now.Format(time.RFC3339),
+				"namespace": map[string]any{"path": "grp", "full_path": "grp", "web_url": "https://gitlab.com/groups/grp"},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	cache := testutil.MemCache{"gitlab:" + host + ":10": true}
+
+	g, err := provider.NewGitLab(srv.URL, "", config.Config{Language: "go", PushedSince: since}, cache)
+	require.NoError(t, err)
+
+	c, err := g.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+
+	assert.Equal(t, "gitlab:"+host+":11", c.Key)
+	assert.Equal(t, "tool", c.Title)
+	assert.Equal(t, 3, c.Stars)
+	assert.Equal(t, "grp", c.Author.Login)
+	assert.Equal(t, "https://gitlab.com/groups/grp", c.Author.ProfileURL)
+}
+
+func TestGitLab_NextResumesFromCursor(t *testing.T) {
+	now := time.Now().UTC()
+	var pages []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		// four pages of seen projects, the fifth has a new one
+		n, _ := strconv.Atoi(page)
+		name := "seen"
+		if n == 5 {
+			name = "fresh"
+		}
+		if n < 5 {
+			w.Header().Set("X-Next-Page", strconv.Itoa(n+1))
+		} else {
+			w.Header().Set("X-Next-Page", "")
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{{
+			"id": n, "name": name, "web_url": "https://gitlab.com/grp/" + name + page,
+			"last_activity_at": now.Format(time.RFC3339),
+		}})
+	}))
+	t.Cleanup(srv.Close)
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	cache := testutil.MemCache{}
+	for n := 1; n < 5; n++ {
+		cache["gitlab:"+host+":"+strconv.Itoa(n)] = true
+	}
+
+	g, err := provider.NewGitLab(srv.URL, "", config.Config{Language: "go", PushedSince: now.Add(-time.Hour)}, cache)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	c, err := g.Next(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+	assert.Equal(t, []string{"1", "2", "3"}, pages)
+
+	pages = nil
+	c, err = g.Next(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "fresh", c.Title)
+	assert.Equal(t, []string{"4", "5"}, pages, "the second cycle picks up after the first")
+	require.NoError(t, g.MarkSeen(ctx, c))
+
+	// the last page is used up, so the listing starts over
+	pages = nil
+	c, err = g.Next(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+	assert.Equal(t, []string{"5"}, pages)
+
+	pages = nil
+	_, err = g.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "1", pages[0])
+}
+
+func TestGitLab_Owner(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace map[string]any
+		login     string
+		profile   string
+	}{
+		{
+			name:      "user",
+			namespace: map[string]any{"path": "alice", "full_path": "alice", "web_url": "https://gitlab.com/alice"},
+			login:     "alice",
+			profile:   "https://gitlab.com/alice",
+		},
+		{
+			name:      "nested group",
+			namespace: map[string]any{"path": "tools", "full_path": "acme/platform/tools", "web_url": "https://gitlab.com/groups/acme/platform/tools"},
+			login:     "acme/platform/tools",
+			profile:   "https://gitlab.com/groups/acme/platform/tools",
+		},
+		{
+			name: "no namespace",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				project := map[string]any{
+					"id": 1, "name": "tool", "web_url": "https://gitlab.com/x/tool",
+					"last_activity_at": time.Now().Format(time.RFC3339),
+				}
+				if tc.namespace != nil {
+					project["namespace"] = tc.namespace
+				}
+				_ = json.NewEncoder(w).Encode([]map[string]any{project})
+			}))
+			t.Cleanup(srv.Close)
+
+			g, err := provider.NewGitLab(srv.URL, "", config.Config{PushedSince: time.Now().Add(-time.Hour)}, testutil.MemCache{})
+			require.NoError(t, err)
+
+			c, err := g.Next(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			assert.Equal(t, tc.login, c.Author.Login)
+			assert.Equal(t, tc.profile, c.Author.ProfileURL)
+		})
+	}
+}
+
+func TestGitLab_NextAppliesQualityAndLicense(t *testing.T) {
+	now := time.Now().UTC()
+	var lookups []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/projects", func(w http.ResponseWriter, _ *http.Request) {
+		project := func(id int, edit func(map[string]any)) map[string]any {
+			p := map[string]any{
+				"id": id, "name": fmt.Sprintf("p%d", id), "description": "does things",
+				"web_url":          fmt.Sprintf("https://gitlab.com/grp/p%d", id),
+				"last_activity_at": now.Format(time.RFC3339),
+			}
+			edit(p)
+			return p
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			project(1, func(p map[string]any) { p["forked_from_project"] = map[string]any{"id": 99} }),
+			project(2, func(p map[string]any) { p["mirror"] = true }),
+			project(3, func(p map[string]any) { p["description"] = "" }),
+			project(4, func(map[string]any) {}),
+			project(5, func(map[string]any) {}),
+			project(6, func(map[string]any) {}),
+		})
+	})
+	mux.HandleFunc("/api/v4/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.URL.Query().Get("license"))
+		lookups = append(lookups, r.PathValue("id"))
+
+		license := map[string]any{
+			"4": nil,
+			"5": map[string]any{"key": "other"},
+			"6": map[string]any{"key": "apache-2.0", "name": "Apache License 2.0"},
+		}[r.PathValue("id")]
+		_ = json.NewEncoder(w).Encode(map[string]any{"license": license})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	cfg := config.Config{
+		Language:    "go",
+		PushedSince: now.Add(-time.Hour),
+		License:     config.LicensePolicy{Required: true, Allowed: config.OSILicenses},
+		Quality:     config.Quality{ExcludeForks: true, ExcludeMirrors: true, RequireDescription: true},
+	}
+	g, err := provider.NewGitLab(srv.URL, "", cfg, testutil.MemCache{})
+	require.NoError(t, err)
+
+	c, err := g.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "p6", c.Title)
+	assert.Equal(t, "Apache-2.0", c.License)
+	assert.Equal(t, []string{"4", "5", "6"}, lookups, "filtered projects aren't looked up")
+}
+
+func TestGitLab_NextSkipsLicenseLookupWithoutPolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v4/projects", r.URL.Path, "no license lookup")
+		_ = json.NewEncoder(w).Encode([]map[string]any{{
+			"id": 1, "name": "tool", "web_url": "https://gitlab.com/grp/tool",
+			"last_activity_at": time.Now().Format(time.RFC3339),
+		}})
+	}))
+	t.Cleanup(srv.Close)
+
+	g, err := provider.NewGitLab(srv.URL, "", config.Config{PushedSince: time.Now().Add(-time.Hour)}, testutil.MemCache{})
+	require.NoError(t, err)
+
+	c, err := g.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Empty(t, c.License)
+}
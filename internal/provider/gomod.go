@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// Module is what we read from a repo's go.mod.
+type Module struct {
+	Path      string
+	GoVersion string // the go directive, empty when missing
+	Deps      int    // direct requirements, "// indirect" ones excluded
+}
+
+// Summary describes the module for the post, e.g. "Go 1.24 · 3 deps".
+func (m *Module) Summary() string {
+	var parts []string
+	if m.GoVersion != "" {
+		parts = append(parts, "Go "+m.GoVersion)
+	}
+	switch m.Deps {
+	case 0:
+		parts = append(parts, "no deps")
+	case 1:
+		parts = append(parts, "1 dep")
+	default:
+		parts = append(parts, fmt.Sprintf("%d deps", m.Deps))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// goModule fetches and parses go.mod at the root of the repo's default
+// branch. Returns (nil, nil) when there is none.
+func (p Provider) goModule(ctx context.Context, repo *github.Repository) (*Module, error) {
+	var file *github.RepositoryContent
+	err := p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+		file, _, resp, err = p.GitHubRepoClient.GetContents(ctx, repo.GetOwner().GetLogin(), repo.GetName(), "go.mod", nil)
+		return resp, err
+	})
+	if isNotFound(err) || (err == nil && file == nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("go.mod of %s: %w", repo.GetFullName(), err)
+	}
+
+	data, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("decode go.mod of %s: %w", repo.GetFullName(), err)
+	}
+	m, err := parseGoMod(data)
+	if err != nil {
+		return nil, fmt.Errorf("go.mod of %s: %w", repo.GetFullName(), err)
+	}
+	return m, nil
+}
+
+// parseGoMod reads the module path, go directive and direct requirements.
+// It only understands as much of the format as we need; other directives
+// are skipped.
+func parseGoMod(data string) (*Module, error) {
+	m := &Module{}
+	block := ""
+
+	for line := range strings.Lines(data) {
+		line, comment, _ := strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		indirect := strings.TrimSpace(comment) == "indirect"
+
+		if block != "" {
+			switch {
+			case len(fields) == 1 && fields[0] == ")":
+				block = ""
+			case block == "require" && len(fields) >= 2 && !indirect:
+				m.Deps++
+			}
+			continue
+		}
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch verb := fields[0]; {
+		case fields[1] == "(":
+			block = verb
+		case verb == "module":
+			path, err := unquote(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("module path: %w", err)
+			}
+			m.Path = path
+		case verb == "go":
+			m.GoVersion = fields[1]
+		case verb == "require" && len(fields) >= 3 && !indirect:
+			m.Deps++
+		}
+	}
+
+	if m.Path == "" {
+		return nil, errors.New("no module directive")
+	}
+	return m, nil
+}
+
+func unquote(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "`") {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
@@ -0,0 +1,85 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+const goMod = `module github.com/owner/repo2/v2 // trailing comment
+
+go 1.24.0
+
+require github.com/single/dep v1.0.0
+
+require (
+	github.com/a/dep v1.2.3
+	github.com/b/dep v0.1.0 // indirect
+	golang.org/x/c v0.5.0
+)
+
+replace (
+	github.com/a/dep => ../dep
+)
+`
+
+func TestProvider_NextReadsGoModule(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(searchResult(2, 1, 2))
+	})
+	mux.HandleFunc("/repos/owner/repo2/contents/go.mod", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(goMod)),
+		})
+	})
+	// repo1 has no go.mod, the mux answers 404
+
+	cfg := config.Config{
+		PushedSince: time.Now().Add(-24 * time.Hour),
+		Quality:     config.Quality{RequireGoModule: true},
+	}
+	p := githubProvider(t, mux, cfg, testutil.MemCache{})
+
+	for range 5 {
+		c, err := p.Next(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, "repo:2", c.Key)
+
+		require.NotNil(t, c.Module)
+		assert.Equal(t, "github.com/owner/repo2/v2", c.Module.Path)
+		assert.Equal(t, "1.24.0", c.Module.GoVersion)
+		assert.Equal(t, 3, c.Module.Deps)
+	}
+}
+
+func TestModule_Summary(t *testing.T) {
+	testCases := []struct {
+		name string
+		mod  provider.Module
+		want string
+	}{
+		{"version and deps", provider.Module{GoVersion: "1.24.0", Deps: 3}, "Go 1.24.0 · 3 deps"},
+		{"single dep", provider.Module{GoVersion: "1.22", Deps: 1}, "Go 1.22 · 1 dep"},
+		{"no deps", provider.Module{GoVersion: "1.21", Deps: 0}, "Go 1.21 · no deps"},
+		{"no go directive", provider.Module{Deps: 2}, "2 deps"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.mod.Summary())
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/till/golangoss-bluesky/internal/config"
+)
+
+// maxTagLength is Bluesky's limit for a tag, in characters.
+const maxTagLength = 64
+
+// hashtags returns the post's hashtags: the lead tag followed by the
+// repo's topics, renamed per cfg.Hashtags.Map. Duplicates and topics that
+// aren't valid Bluesky tags are dropped, the rest is capped at
+// cfg.Hashtags.Max tags.
+func hashtags(cfg config.Config, topics []string) string {
+	h := cfg.Hashtags
+	lead := leadTag(cfg)
+	if lead == "" {
+		lead = "go"
+	}
+
+	tags := []string{lead}
+	for _, topic := range topics {
+		if h.Max > 0 && len(tags) >= h.Max {
+			break
+		}
+
+		tag := strings.ToLower(strings.TrimSpace(topic))
+		if mapped, ok := h.Map[tag]; ok {
+			tag = mapped
+		}
+		if !validTag(tag) || slices.Contains(tags, tag) {
+			continue
+		}
+		if h.MaxLength > 0 && utf8.RuneCountInString(tag) > h.MaxLength {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+
+	return "#" + strings.Join(tags, " #")
+}
+
+// leadTag returns the campaign's hashtag, or its language, without the
+// "#". It's "" when there's neither, e.g. for a topic-only campaign, or
+// when it isn't a valid tag.
+func leadTag(cfg config.Config) string {
+	lead := strings.TrimPrefix(cfg.Hashtags.Lead, "#")
+	if lead == "" {
+		lead = strings.ToLower(cfg.Language)
+	}
+	if !validTag(lead) {
+		return ""
+	}
+	return lead
+}
+
+// validTag reports whether Bluesky would recognize "#"+tag as a hashtag: no
+// whitespace or '#', not only digits and punctuation, and no trailing
+// punctuation, which the Bluesky clients strip off.
+func validTag(tag string) bool {
+	if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+		return false
+	}
+	if strings.ContainsFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || r == '#' }) {
+		return false
+	}
+	if !strings.ContainsFunc(tag, func(r rune) bool { return !unicode.IsDigit(r) && !unicode.IsPunct(r) }) {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(tag)
+	return !unicode.IsPunct(last)
+}
@@ -0,0 +1,57 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestProvider_HashtagsFromTopics(t *testing.T) {
+	tags := config.Hashtags{
+		Map:       map[string]string{"golang": "go", "k8s": "kubernetes", "hacktoberfest": ""},
+		Max:       4,
+		MaxLength: 12,
+	}
+
+	testCases := []struct {
+		name   string
+		tags   config.Hashtags
+		topics []string
+		want   string
+	}{
+		{"no topics", tags, nil, "#go"},
+		{"mapped and deduplicated", tags, []string{"golang", "k8s", "kubernetes"}, "#go #kubernetes"},
+		{"mapped to nothing", tags, []string{"hacktoberfest", "cli"}, "#go #cli"},
+		{"invalid tags dropped", tags, []string{"2024", "has space", "c#", "v1.", "web-3"}, "#go #web-3"},
+		{"too long", tags, []string{"observability", "otel"}, "#go #otel"},
+		{"capped", tags, []string{"cli", "tui", "terminal", "shell"}, "#go #cli #tui #terminal"},
+		{"campaign lead tag", config.Hashtags{Lead: "#templ"}, []string{"golang", "templ"}, "#templ #golang"},
+		{"no limits", config.Hashtags{}, []string{"observability", "cli", "tui", "terminal"}, "#go #observability #cli #tui #terminal"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+				res := searchResult(1, 1)
+				res["items"].([]map[string]any)[0]["topics"] = tc.topics
+				_ = json.NewEncoder(w).Encode(res)
+			})
+
+			cfg := config.Config{Language: "Go", PushedSince: time.Now().Add(-24 * time.Hour), Hashtags: tc.tags}
+			p := githubProvider(t, mux, cfg, testutil.MemCache{})
+
+			c, err := p.Next(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			assert.Equal(t, tc.want, c.Hashtag)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// Response cache backends for GitHubOptions.ResponseCache.
+	ResponseCacheMemory = "memory"
+	ResponseCacheS3     = "s3"
+
+	// responseCacheTTL is how long a stored response is kept in S3. An expired
+	// entry only costs one unconditional request.
+	responseCacheTTL = 7 * 24 * time.Hour
+	// memoryResponseLimit caps the in-memory backend.
+	memoryResponseLimit = 1000
+)
+
+// cachedResponse is what we keep to answer a 304.
+type cachedResponse struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// responseStore persists cached responses by URL.
+type responseStore interface {
+	load(ctx context.Context, url string) (cachedResponse, bool)
+	save(ctx context.Context, url string, r cachedResponse)
+}
+
+// newResponseStore returns the backend for kind, or nil when caching is off.
+func newResponseStore(kind string, cc Cache) (responseStore, error) {
+	switch kind {
+	case "", "off":
+		return nil, nil
+	case ResponseCacheMemory:
+		return &memoryResponses{entries: map[string]cachedResponse{}}, nil
+	case ResponseCacheS3:
+		return s3Responses{cache: cc}, nil
+	default:
+		return nil, fmt.Errorf("unknown response cache %q", kind)
+	}
+}
+
+// etagTransport makes GET requests conditional on the ETag of the last
+// response for the same URL. GitHub doesn't count 304s against the rate
+// limit, so repeating a search whose results haven't changed is free.
+type etagTransport struct {
+	next  http.RoundTripper
+	store responseStore
+}
+
+func (t etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	key := req.URL.String()
+
+	cached, ok := t.store.load(ctx, key)
+	if ok {
+		req = req.Clone(ctx)
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return cached.response(req, resp.Header), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	t.store.save(ctx, key, cachedResponse{ETag: etag, Header: resp.Header, Body: body})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+// response rebuilds the 200 from the cache. Rate limit headers are taken
+// from the 304 so the tracker sees the current budget.
+func (c cachedResponse) response(req *http.Request, fresh http.Header) *http.Response {
+	header := c.Header.Clone()
+	for k, v := range fresh {
+		if strings.HasPrefix(k, "X-Ratelimit-") {
+			header[k] = v
+		}
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.Body)),
+		ContentLength: int64(len(c.Body)),
+		Request:       req,
+	}
+}
+
+// memoryResponses keeps responses for the lifetime of the process.
+type memoryResponses struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+func (m *memoryResponses) load(_ context.Context, url string) (cachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.entries[url]
+	return r, ok
+}
+
+func (m *memoryResponses) save(_ context.Context, url string, r cachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.entries[url]; !ok && len(m.entries) >= memoryResponseLimit {
+		// evict whatever the map hands us first, good enough for a cache
+		for k := range m.entries {
+			delete(m.entries, k)
+			break
+		}
+	}
+	m.entries[url] = r
+}
+
+// s3Responses keeps responses in the cache bucket so they survive restarts.
+// Keys are "http:<sha256 of the URL>".
+type s3Responses struct {
+	cache Cache
+}
+
+func (s s3Responses) key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "http:" + hex.EncodeToString(sum[:])
+}
+
+func (s s3Responses) load(ctx context.Context, url string) (cachedResponse, bool) {
+	var r cachedResponse
+
+	raw, err := s.cache.Get(ctx, s.key(url))
+	if err != nil {
+		if err != redis.Nil {
+			slog.WarnContext(ctx, "response cache load failed", "err", err)
+		}
+		return r, false
+	}
+	if err := json.Unmarshal([]byte(raw), &r); err != nil || r.ETag == "" {
+		return r, false
+	}
+	return r, true
+}
+
+func (s s3Responses) save(ctx context.Context, url string, r cachedResponse) {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	if err := s.cache.Set(ctx, s.key(url), string(raw), responseCacheTTL); err != nil {
+		slog.WarnContext(ctx, "response cache save failed", "err", err)
+	}
+}
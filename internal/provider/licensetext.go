@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+)
+
+// licenseHead is how much of a license file is looked at. Every license
+// we recognize names itself within it, and the text further down may
+// mention other licenses, e.g. the GPL pointing to the LGPL.
+const licenseHead = 2000
+
+var spdxTag = regexp.MustCompile(`SPDX-License-Identifier:\s*([A-Za-z0-9.+-]+)`)
+
+// licenseTexts identify the OSI licenses by phrases from their text. The
+// title checks are case-sensitive, as other licenses are often mentioned
+// in title case. Order matters: LGPL before GPL, ISC before 0BSD, and
+// BSD-3-Clause before BSD-2-Clause.
+var licenseTexts = []struct {
+	spdx    string
+	phrases []string
+}{
+	{"AGPL-3.0", []string{"GNU AFFERO GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-3.0", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"LGPL-2.1", []string{"GNU LESSER GENERAL PUBLIC LICENSE", "Version 2.1"}},
+	{"GPL-3.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 3"}},
+	{"GPL-2.0", []string{"GNU GENERAL PUBLIC LICENSE", "Version 2"}},
+	{"Apache-2.0", []string{"Apache License", "Version 2.0"}},
+	{"MPL-2.0", []string{"Mozilla Public License", "2.0"}},
+	{"EPL-2.0", []string{"Eclipse Public License", "2.0"}},
+	{"Unlicense", []string{"This is free and unencumbered software released into the public domain"}},
+	{"MIT", []string{"Permission is hereby granted, free of charge"}},
+	{"BSD-3-Clause", []string{"Redistribution and use in source and binary forms", "Neither the name"}},
+	{"BSD-2-Clause", []string{"Redistribution and use in source and binary forms"}},
+	{"ISC", []string{"Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted, provided that"}},
+	{"0BSD", []string{"Permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted."}},
+}
+
+// isLicenseFile reports whether name is what a repo's license file is
+// usually called, e.g. "LICENSE", "LICENCE.md" or "COPYING".
+func isLicenseFile(name string) bool {
+	base, _, _ := strings.Cut(strings.ToUpper(name), ".")
+	switch base {
+	case "LICENSE", "LICENCE", "COPYING":
+		return true
+	}
+	return false
+}
+
+// licenseFromText returns the SPDX ID of the license in a license file, ""
+// when it's none we recognize.
+func licenseFromText(text string) string {
+	if m := spdxTag.FindStringSubmatch(text); m != nil {
+		return spdxID(m[1])
+	}
+
+	head := strings.Join(strings.Fields(text), " ")
+	if len(head) > licenseHead {
+		head = head[:licenseHead]
+	}
+	for _, l := range licenseTexts {
+		if containsAll(head, l.phrases) {
+			return l.spdx
+		}
+	}
+	return ""
+}
+
+func containsAll(s string, subs []string) bool {
+	for _, sub := range subs {
+		if !strings.Contains(s, sub) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,126 +1,385 @@
-// Package provider picks a GitHub repo to post about. It runs a filtered
-// search (language, non-archived, recently pushed), skips repos we've already
-// seen via the shared cache, and enriches the pick with the owner's GitHub
-// login plus Bluesky handle when they've linked one on their GitHub profile.
+// Package provider picks a repo to post about. The GitHub provider runs a
+// filtered search (language, non-archived, recently pushed), skips repos we've
+// already seen via the shared cache, and enriches the pick with the owner's
+// GitHub login plus the Bluesky and fediverse accounts they've linked on
+// their GitHub profile. Other forges plug in as additional Sources.
 package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"math/rand/v2"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/go-github/v90/github"
-	"github.com/till/golangoss-bluesky/internal/cache"
 	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/submission"
 )
 
+// Source is anything that can feed the posting loop. Next picks a candidate,
+// MarkSeen makes sure it isn't picked again and Enrich fills in the details
+// that cost extra API calls.
+type Source interface {
+	// Next returns an unseen candidate, or nil when there is none this cycle.
+	Next(ctx context.Context) (*Content, error)
+	// Enrich adds details such as the author's social handles to c.
+	Enrich(ctx context.Context, c *Content) error
+	// MarkSeen records c in the cache so Next skips it from now on.
+	MarkSeen(ctx context.Context, c *Content) error
+}
+
+// Cache is the subset of cache.ClientS3 the providers use, so tests can
+// swap in an in-memory map.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value any, exp time.Duration) error
+}
+
 // Content is what the provider hands back to the poster.
 type Content struct {
+	Key         string // cache key, unique across sources (e.g. "repo:<id>")
 	Title       string
+	FullName    string // "owner/name" on the forge hosting the repo
 	Description string
 	URL         string
 	Stars       int
-	Hashtag     string
-	Author      Author
+	License     string // SPDX ID, empty when unknown
+	// StarGrowth is how many stars the repo gained over GrowthWindow, 0 when
+	// we have no earlier snapshot. GrowthWindow is at most the trend window,
+	// shorter when the repo was first seen later.
+	StarGrowth   int
+	GrowthWindow time.Duration
+	Topics       []string
+	Hashtag      string
+	// Module is parsed from the repo's go.mod, nil when there is none or
+	// the source doesn't look it up.
+	Module *Module
+	Author Author
+
+	// Network holds the cache keys of the repo's parent and fork source,
+	// which are marked seen along with Key so the rest of the fork network
+	// isn't featured after it.
+	Network []string
+	// Aliases are other URLs of the project, such as its homepage or the
+	// upstream it mirrors, used to spot mirrors of featured repos.
+	Aliases []string
 }
 
-// Author is the repo owner. BlueskyHandle is set when the owner listed a
-// bsky.app profile in their GitHub social accounts; otherwise empty.
+// Author is the repo owner. Login is the owner's name on the forge hosting the
+// repo and ProfileURL links to it. The other accounts are filled in from the
+// social accounts and website on the owner's GitHub profile, and are empty
+// when the owner didn't list one.
 type Author struct {
-	GitHubLogin   string
+	Login      string
+	ProfileURL string
+
+	// BlueskyHandle is the owner's handle, including custom domains.
+	// BlueskyDID is set instead when the profile links to a did:plc or
+	// did:web URL.
 	BlueskyHandle string
+	BlueskyDID    string
+
+	// Fediverse is the owner's "@user@instance" address on Mastodon and the
+	// like, FediverseURL their profile page.
+	Fediverse    string
+	FediverseURL string
+}
+
+// OptOutList reports maintainers who asked not to be featured.
+type OptOutList interface {
+	Has(ctx context.Context, login string) (bool, error)
 }
 
+// SubmissionQueue holds the repos suggested for posting.
+type SubmissionQueue interface {
+	List(ctx context.Context) ([]submission.Submission, error)
+	Remove(ctx context.Context, s submission.Submission) error
+}
+
+// socialCacheTTL is how long a login's social accounts are cached.
+const socialCacheTTL = 7 * 24 * time.Hour
+
+var _ Source = Provider{}
+
 type Provider struct {
 	Config      config.Config
-	CacheClient cache.ClientS3
+	CacheClient Cache
+
+	// Scorer ranks search results; nil picks uniformly at random.
+	Scorer Scorer
+	// OptOuts is checked before a repo is picked; nil disables the check.
+	OptOuts OptOutList
+	// Rates tracks GitHub's rate limits; nil disables the tracking.
+	Rates *RateTracker
+	// Submissions is the queue the Submissions source drains; nil
+	// disables it.
+	Submissions SubmissionQueue
 
 	GitHubSearchClient *github.SearchService
 	GitHubUserClient   *github.UsersService
+	GitHubRepoClient   *github.RepositoriesService
+}
+
+// GitHubOptions configures the GitHub API client.
+type GitHubOptions struct {
+	Token string
+	// App authenticates as a GitHub App installation instead of with Token
+	// when App.AppID is set.
+	App AppCredentials
+	// ResponseCache makes requests conditional on the last ETag so unchanged
+	// responses don't count against the quota: ResponseCacheMemory,
+	// ResponseCacheS3 or "" to disable.
+	ResponseCache string
 }
 
-func NewProvider(apiKey string, cfg config.Config, cacheClient cache.ClientS3) (Provider, error) {
+func NewProvider(opts GitHubOptions, cfg config.Config, cacheClient Cache) (Provider, error) {
 	slog.Info("New Github Provider")
-	p := Provider{Config: cfg, CacheClient: cacheClient}
+	p := Provider{Config: cfg, CacheClient: cacheClient, Scorer: WeightedScorer(cfg.Weights)}
+
+	transport := http.DefaultTransport
 
-	gh, err := github.NewClient(github.WithAuthToken(apiKey))
+	store, err := newResponseStore(opts.ResponseCache, cacheClient)
+	if err != nil {
+		return p, err
+	}
+	if store != nil {
+		transport = etagTransport{next: transport, store: store}
+	}
+
+	var clientOpts []github.ClientOptionsFunc
+	if opts.App.AppID != 0 {
+		slog.Info("Authenticating as GitHub App", "app_id", opts.App.AppID)
+		src, err := NewAppTokenSource(opts.App, "")
+		if err != nil {
+			return p, err
+		}
+		transport = src.Transport(transport)
+	} else {
+		clientOpts = append(clientOpts, github.WithAuthToken(opts.Token))
+	}
+	clientOpts = append(clientOpts, github.WithTransport(transport))
+
+	gh, err := github.NewClient(clientOpts...)
 	if err != nil {
 		return p, err
 	}
 
 	p.GitHubSearchClient = gh.Search
 	p.GitHubUserClient = gh.Users
+	p.GitHubRepoClient = gh.Repositories
 
 	return p, nil
 }
 
-// GetContentToPublish picks a random uncached repo matching the query and
-// enriches it with the owner's social handles. Returns (nil, nil) when every
-// candidate on the fetched page is already cached.
-func (p Provider) GetContentToPublish(ctx context.Context) (*Content, error) {
-	res, _, err := p.GitHubSearchClient.Repositories(ctx, p.buildQuery(), &github.SearchOptions{
-		Sort:        "updated",
-		Order:       "desc",
-		ListOptions: github.ListOptions{PerPage: 100},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("github search: %w", err)
+// GetContentToPublish asks src for a candidate, marks it seen and enriches
+// it. Returns (nil, nil) when src has nothing left to offer. A failed
+// enrichment is logged, the candidate is still returned.
+func GetContentToPublish(ctx context.Context, src Source) (*Content, error) {
+	c, err := src.Next(ctx)
+	if err != nil || c == nil {
+		return nil, err
 	}
-	if len(res.Repositories) == 0 {
-		return nil, nil
+	if err := src.MarkSeen(ctx, c); err != nil {
+		return nil, err
 	}
+	if err := src.Enrich(ctx, c); err != nil {
+		slog.WarnContext(ctx, "enrich failed", "key", c.Key, "err", err)
+	}
+	return c, nil
+}
 
-	for _, idx := range rand.Perm(len(res.Repositories)) {
-		repo := res.Repositories[idx]
-		if repo.ID == nil {
-			continue
+// Next picks an uncached repo matching the query, ranked by p.Scorer. It
+// resumes the search where the previous cycle left off and walks further
+// pages and older pushed: windows until it finds a candidate or runs out of
+// its per-cycle page budget. Returns (nil, nil) in the latter case.
+func (p Provider) Next(ctx context.Context) (*Content, error) {
+	cur := p.loadCursor(ctx)
+	windows := searchWindows(p.Config.PushedSince, time.Now().UTC())
+
+	for range searchPagesPerCycle {
+		if cur.Window >= len(windows) {
+			slog.DebugContext(ctx, "search exhausted, starting over")
+			cur = searchCursor{}
 		}
-		key := fmt.Sprintf("repo:%d", *repo.ID)
+		cur.Page = max(cur.Page, 1)
 
-		seen, err := p.isSeen(ctx, key)
+		var res *github.RepositoriesSearchResult
+		err := p.withRateLimit(ctx, rateSearch, func() (resp *github.Response, err error) {
+			res, resp, err = p.GitHubSearchClient.Repositories(ctx, p.buildQuery(windows[cur.Window]), &github.SearchOptions{
+				Sort:        "updated",
+				Order:       "desc",
+				ListOptions: github.ListOptions{PerPage: searchPerPage, Page: cur.Page},
+			})
+			return resp, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("github search: %w", err)
+		}
+
+		c, err := p.pick(ctx, res.Repositories)
 		if err != nil {
 			return nil, err
 		}
-		if seen {
-			continue
+		if c != nil {
+			p.saveCursor(ctx, cur)
+			return c, nil
 		}
-		if err := p.markSeen(ctx, key); err != nil {
+
+		cur = cur.advance(res.GetTotal())
+	}
+
+	p.saveCursor(ctx, cur)
+	return nil, nil
+}
+
+// pick returns the repo from the page that isn't cached, trying candidates in
+// score order, or nil when all are cached.
+func (p Provider) pick(ctx context.Context, repos []*github.Repository) (*Content, error) {
+	now := time.Now()
+	growth := p.starGrowth(ctx, now, repos)
+
+	order := rand.Perm(len(repos))
+	var scores []Score
+	if p.Scorer != nil {
+		scores = make([]Score, len(repos))
+		for i, repo := range repos {
+			scores[i] = p.Scorer(repo, now)
+			if g, ok := growth[repo.GetID()]; ok && p.Config.Weights.StarGrowth != 0 {
+				v := p.Config.Weights.StarGrowth * logScale(g.Gained, 3)
+				scores[i].Parts["star_growth"] = v
+				scores[i].Total += v
+			}
+			if f := p.Config.Policy.BoostFactor(policyRepo(repo)); f != 1 {
+				scores[i].Parts["boost"] = scores[i].Total * (f - 1)
+				scores[i].Total *= f
+			}
+		}
+		order = rankByScore(scores, p.Config.PickTop)
+	}
+
+	for _, idx := range order {
+		repo := repos[idx]
+		c, err := p.check(ctx, repo)
+		if err != nil {
 			return nil, err
 		}
-		return p.toContent(ctx, repo), nil
+		if c == nil {
+			continue
+		}
+
+		if scores != nil {
+			slog.DebugContext(ctx, "picked candidate",
+				"repo", repo.GetFullName(),
+				"score", scores[idx].Total,
+				"parts", scores[idx].Parts)
+		}
+
+		if g := growth[*repo.ID]; g.Gained > 0 {
+			c.StarGrowth, c.GrowthWindow = g.Gained, g.Over
+		}
+		return c, nil
 	}
 	return nil, nil
 }
 
-func (p Provider) buildQuery() string {
-	query := strings.Builder{}
+// Enrich looks up the owner's social accounts, and takes the description
+// from the README when the repo's own is missing or too short. It's skipped
+// when the API budget is running low, to keep it for the search.
+func (p Provider) Enrich(ctx context.Context, c *Content) error {
+	if p.Rates.low(rateCore) {
+		slog.InfoContext(ctx, "github budget low, skipping enrichment", "key", c.Key)
+		return nil
+	}
+
+	if needsDescription(c.Description) {
+		desc, err := p.readmeDescription(ctx, c.FullName)
+		if err != nil {
+			slog.WarnContext(ctx, "readme description failed", "repo", c.FullName, "err", err)
+		} else if desc != "" {
+			c.Description = desc
+		}
+	}
+
+	if c.Author.Login == "" {
+		return nil
+	}
+	return p.fetchAuthor(ctx, &c.Author)
+}
+
+// MarkSeen caches the repo's key along with its fork network, URLs and
+// module path, and adds it to the featured list the release watcher can
+// follow.
+func (p Provider) MarkSeen(ctx context.Context, c *Content) error {
+	if err := markSeen(ctx, p.CacheClient, c.Key); err != nil {
+		return err
+	}
+	if err := markDuplicates(ctx, p.CacheClient, c); err != nil {
+		return err
+	}
+	if err := p.recordFeatured(ctx, c.FullName); err != nil {
+		slog.WarnContext(ctx, "recording featured repo failed", "repo", c.FullName, "err", err)
+	}
+	return nil
+}
+
+func repoKey(id int64) string {
+	return fmt.Sprintf("repo:%d", id)
+}
+
+func (p Provider) buildQuery(w window) string {
+	var terms []string
 
 	if p.Config.Language != "" {
-		query.WriteString("language:")
-		query.WriteString(p.Config.Language)
+		terms = append(terms, "language:"+p.Config.Language)
 	}
 
 	if !p.Config.Archived {
-		if query.Len() > 0 {
-			query.WriteString(" ")
-		}
-		query.WriteString("archived:false")
+		terms = append(terms, "archived:false")
 	}
 
-	if query.Len() > 0 {
-		query.WriteString(" ")
+	if p.Config.Topic != "" {
+		terms = append(terms, "topic:"+p.Config.Topic)
 	}
-	query.WriteString("pushed:>=")
-	query.WriteString(p.Config.PushedSince.Format("2006-01-02"))
 
-	return query.String()
+	q := p.Config.Quality
+	switch {
+	case q.MinStars > 0 && q.MaxStars > 0:
+		terms = append(terms, fmt.Sprintf("stars:%d..%d", q.MinStars, q.MaxStars))
+	case q.MinStars > 0:
+		terms = append(terms, fmt.Sprintf("stars:>=%d", q.MinStars))
+	case q.MaxStars > 0:
+		terms = append(terms, fmt.Sprintf("stars:<=%d", q.MaxStars))
+	}
+	if q.MinSizeKB > 0 {
+		terms = append(terms, fmt.Sprintf("size:>=%d", q.MinSizeKB))
+	}
+	if q.ExcludeTemplates {
+		terms = append(terms, "template:false")
+	}
+	if q.ExcludeMirrors {
+		terms = append(terms, "mirror:false")
+	}
+	// forks are excluded from search results by default, and there's no
+	// qualifier for "has a description"; reject catches both
+
+	// GitHub can't OR license qualifiers, so only a single allowed license
+	// narrows the query. Everything else is post-filtered in reject.
+	if allowed := p.Config.License.Allowed; len(allowed) == 1 {
+		terms = append(terms, "license:"+strings.ToLower(allowed[0]))
+	}
+
+	terms = append(terms, "pushed:"+w.From.Format("2006-01-02")+".."+w.To.Format("2006-01-02"))
+
+	return strings.Join(terms, " ")
 }
 
-func (p Provider) isSeen(ctx context.Context, key string) (bool, error) {
-	_, err := p.CacheClient.Get(ctx, key)
+func isSeen(ctx context.Context, cc Cache, key string) (bool, error) {
+	_, err := cc.Get(ctx, key)
 	if err == redis.Nil {
 		return false, nil
 	}
@@ -130,55 +389,189 @@ func (p Provider) isSeen(ctx context.Context, key string) (bool, error) {
 	return true, nil
 }
 
-func (p Provider) markSeen(ctx context.Context, key string) error {
-	return p.CacheClient.Set(ctx, key, true, 0)
+func markSeen(ctx context.Context, cc Cache, key string) error {
+	return cc.Set(ctx, key, true, 0)
+}
+
+// check runs repo through the filters, the opt-outs, the cache, the spam
+// classifier and the duplicate detection, and returns it as Content. It
+// returns nil when repo is rejected or was seen already.
+func (p Provider) check(ctx context.Context, repo *github.Repository) (*Content, error) {
+	if repo.ID == nil {
+		return nil, nil
+	}
+
+	if reason := p.reject(repo); reason != "" {
+		slog.DebugContext(ctx, "rejected candidate", "repo", repo.GetFullName(), "reason", reason)
+		return nil, nil
+	}
+	if p.OptOuts != nil {
+		out, err := p.OptOuts.Has(ctx, repo.GetOwner().GetLogin())
+		if err != nil {
+			return nil, err
+		}
+		if out {
+			slog.DebugContext(ctx, "rejected candidate", "repo", repo.GetFullName(), "reason", "owner opted out")
+			return nil, nil
+		}
+	}
+
+	seen, err := isSeen(ctx, p.CacheClient, repoKey(*repo.ID))
+	if err != nil {
+		return nil, err
+	}
+	if seen {
+		return nil, nil
+	}
+
+	reason, err := p.classify(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		slog.DebugContext(ctx, "rejected candidate", "repo", repo.GetFullName(), "reason", reason)
+		return nil, nil
+	}
+
+	desc, ok := p.describe(ctx, repo)
+	if !ok {
+		slog.DebugContext(ctx, "rejected candidate", "repo", repo.GetFullName(), "reason", "no description")
+		return nil, nil
+	}
+
+	network := p.forkNetwork(ctx, repo)
+
+	mod, ok := p.lookupModule(ctx, repo)
+	if !ok {
+		slog.DebugContext(ctx, "rejected candidate", "repo", repo.GetFullName(), "reason", "no go.mod")
+		return nil, nil
+	}
+
+	c := p.toContent(repo, mod)
+	c.Description = desc
+	c.Network = network
+	reason, err = duplicate(ctx, p.CacheClient, c)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		slog.DebugContext(ctx, "rejected candidate", "repo", repo.GetFullName(), "reason", reason)
+		return nil, nil
+	}
+	return c, nil
 }
 
-func (p Provider) toContent(ctx context.Context, repo *github.Repository) *Content {
-	lang := p.Config.Language
-	if lang == "" {
-		lang = "go"
+// lookupModule reads the repo's go.mod. ok is false when the repo should be
+// skipped because Quality.RequireGoModule is set and there's no readable
+// go.mod. Without that requirement the lookup is best effort and skipped
+// when the API budget runs low.
+func (p Provider) lookupModule(ctx context.Context, repo *github.Repository) (mod *Module, ok bool) {
+	required := p.Config.Quality.RequireGoModule
+	if !required && p.Rates.low(rateCore) {
+		return nil, true
+	}
+
+	mod, err := p.goModule(ctx, repo)
+	if err != nil {
+		slog.WarnContext(ctx, "go.mod lookup failed", "repo", repo.GetFullName(), "err", err)
 	}
-	c := &Content{
+	return mod, mod != nil || !required
+}
+
+// toContent converts a search result; mod is nil when the repo has no
+// go.mod or it wasn't looked up.
+func (p Provider) toContent(repo *github.Repository, mod *Module) *Content {
+	return &Content{
+		Key:         repoKey(repo.GetID()),
 		Title:       repo.GetName(),
+		FullName:    repo.GetFullName(),
 		Description: repo.GetDescription(),
 		URL:         repo.GetHTMLURL(),
 		Stars:       repo.GetStargazersCount(),
-		Hashtag:     "#" + strings.ToLower(lang),
+		License:     repo.GetLicense().GetSPDXID(),
+		Topics:      repo.Topics,
+		Hashtag:     hashtags(p.Config, repo.Topics),
+		Module:      mod,
+		Author: Author{
+			Login:      repo.GetOwner().GetLogin(),
+			ProfileURL: repo.GetOwner().GetHTMLURL(),
+		},
+		Aliases: aliases(repo.GetHomepage(), repo.GetMirrorURL()),
 	}
-	if login := repo.GetOwner().GetLogin(); login != "" {
-		c.Author = p.fetchAuthor(ctx, login)
+}
+
+func (p Provider) fetchAuthor(ctx context.Context, a *Author) error {
+	accounts, err := p.socialAccounts(ctx, a.Login)
+	if err != nil {
+		return err
 	}
-	return c
+	a.addAccounts(accounts)
+	return nil
 }
 
-func (p Provider) fetchAuthor(ctx context.Context, login string) Author {
-	a := Author{GitHubLogin: login}
+// socialAccounts returns the profile links login lists on GitHub: their
+// social accounts and the website field. They rarely change, so they're
+// cached per login for socialCacheTTL.
+func (p Provider) socialAccounts(ctx context.Context, login string) ([]socialAccount, error) {
+	key := "social:" + strings.ToLower(login)
+
+	var accounts []socialAccount
+	raw, err := p.CacheClient.Get(ctx, key)
+	if err == nil && json.Unmarshal([]byte(raw), &accounts) == nil {
+		return accounts, nil
+	}
+	if err != nil && err != redis.Nil {
+		slog.WarnContext(ctx, "social accounts cache load failed", "login", login, "err", err)
+	}
 
-	accounts, _, err := p.GitHubUserClient.ListUserSocialAccounts(ctx, login, nil)
+	var listed []*github.SocialAccount
+	err = p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+		listed, resp, err = p.GitHubUserClient.ListUserSocialAccounts(ctx, login, nil)
+		return resp, err
+	})
 	if err != nil {
-		slog.WarnContext(ctx, "social accounts fetch failed", "login", login, "err", err)
-		return a
+		return nil, fmt.Errorf("social accounts of %s: %w", login, err)
+	}
+
+	accounts = make([]socialAccount, 0, len(listed)+1)
+	for _, sa := range listed {
+		accounts = append(accounts, socialAccount{Provider: sa.GetProvider(), URL: sa.GetURL()})
+	}
+
+	var user *github.User
+	err = p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+		user, resp, err = p.GitHubUserClient.Get(ctx, login)
+		return resp, err
+	})
+	switch {
+	case isNotFound(err):
+	case err != nil:
+		return nil, fmt.Errorf("profile of %s: %w", login, err)
+	case user.GetBlog() != "":
+		accounts = append(accounts, socialAccount{Provider: "blog", URL: user.GetBlog()})
 	}
-	for _, sa := range accounts {
-		if h := extractBlueskyHandle(sa.GetURL()); h != "" {
-			a.BlueskyHandle = h
-			return a
+
+	if raw, err := json.Marshal(accounts); err == nil {
+		if err := p.CacheClient.Set(ctx, key, string(raw), socialCacheTTL); err != nil {
+			slog.WarnContext(ctx, "social accounts cache save failed", "login", login, "err", err)
 		}
 	}
-	return a
+	return accounts, nil
 }
 
-// extractBlueskyHandle returns the handle portion of a bsky.app profile URL,
-// or "" if the URL isn't a bsky profile.
-func extractBlueskyHandle(url string) string {
-	const prefix = "https://bsky.app/profile/"
-	if !strings.HasPrefix(url, prefix) {
-		return ""
+// HasBlueskyAccount reports whether login lists the Bluesky account with the
+// given handle or DID on their GitHub profile. Used to check that an opt-out
+// request sent from Bluesky really comes from the maintainer.
+func (p Provider) HasBlueskyAccount(ctx context.Context, login, handle, did string) (bool, error) {
+	a := Author{Login: login}
+	if err := p.fetchAuthor(ctx, &a); err != nil {
+		return false, err
 	}
-	handle := strings.TrimSuffix(strings.TrimPrefix(url, prefix), "/")
-	if handle == "" || strings.ContainsAny(handle, "/?#") {
-		return ""
+	switch {
+	case a.BlueskyHandle != "":
+		return strings.EqualFold(a.BlueskyHandle, handle), nil
+	case a.BlueskyDID != "":
+		return a.BlueskyDID == did, nil
 	}
-	return handle
+	return false, nil
 }
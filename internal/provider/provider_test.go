@@ -0,0 +1,264 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+// githubProvider returns a Provider talking to a fake GitHub API served by mux.
+func githubProvider(t *testing.T, mux *http.ServeMux, cfg config.Config, cache provider.Cache) provider.Provider {
+	t.Helper()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	base := srv.URL + "/"
+	gh, err := github.NewClient(github.WithURLs(&base, &base), github.WithDisableRateLimitCheck())
+	require.NoError(t, err)
+
+	return provider.Provider{
+		Config:             cfg,
+		CacheClient:        cache,
+		GitHubSearchClient: gh.Search,
+		GitHubUserClient:   gh.Users,
+		GitHubRepoClient:   gh.Repositories,
+	}
+}
+
+func searchResult(total int, ids ...int64) map[string]any {
+	items := make([]map[string]any, 0, len(ids))
+	for _, id := range ids {
+		items = append(items, map[string]any{
+			"id":               id,
+			"name":             fmt.Sprintf("repo%d", id),
+			"html_url":         fmt.Sprintf("https://github.com/owner/repo%d", id),
+			"stargazers_count": 42,
+			"owner":            map[string]any{"login": "owner", "html_url": "https://github.com/owner"},
+		})
+	}
+	return map[string]any{"total_count": total, "items": items}
+}
+
+func TestProvider_NextWalksPages(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		switch page {
+		case 0, 1:
+			_ = json.NewEncoder(w).Encode(searchResult(150, 1, 2))
+		default:
+			_ = json.NewEncoder(w).Encode(searchResult(150, 3))
+		}
+	})
+
+	now := time.Now().UTC()
+	cfg := config.Config{Language: "go", PushedSince: now.Add(-10 * 24 * time.Hour)}
+	cache := testutil.MemCache{"repo:1": true, "repo:2": true}
+	p := githubProvider(t, mux, cfg, cache)
+
+	c, err := p.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:3", c.Key)
+	assert.Equal(t, "owner", c.Author.Login)
+
+	require.Len(t, queries, 2)
+	assert.Equal(t, fmt.Sprintf("language:go archived:false pushed:%s..%s",
+		cfg.PushedSince.Format("2006-01-02"), now.Format("2006-01-02")), queries[0])
+
+	assert.JSONEq(t, `{"window":0,"page":2}`, cache["cursor:github-search"].(string))
+}
+
+func TestProvider_NextMovesToOlderWindow(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		_ = json.NewEncoder(w).Encode(searchResult(1, 1))
+	})
+
+	cfg := config.Config{Language: "go", PushedSince: time.Now().UTC().Add(-90 * 24 * time.Hour)}
+	cache := testutil.MemCache{"repo:1": true}
+	p := githubProvider(t, mux, cfg, cache)
+
+	c, err := p.Next(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	// one page per window, three pages per cycle
+	require.Len(t, queries, 3)
+	assert.NotEqual(t, queries[0], queries[1])
+	assert.NotEqual(t, queries[1], queries[2])
+	assert.JSONEq(t, `{"window":3,"page":1}`, cache["cursor:github-search"].(string))
+}
+
+func TestProvider_NextAppliesQualityFilters(t *testing.T) {
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+		res := searchResult(4, 1, 2, 3, 4)
+		items := res["items"].([]map[string]any)
+		items[0]["fork"] = true
+		items[1]["is_template"] = true
+		items[2]["description"] = "   "
+		items[3]["description"] = "the one"
+		_ = json.NewEncoder(w).Encode(res)
+	})
+
+	cfg := config.Config{
+		PushedSince: time.Now().UTC().Add(-24 * time.Hour),
+		Quality: config.Quality{
+			MinStars:           10,
+			ExcludeForks:       true,
+			ExcludeTemplates:   true,
+			RequireDescription: true,
+		},
+	}
+	p := githubProvider(t, mux, cfg, testutil.MemCache{})
+
+	c, err := p.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:4", c.Key)
+	assert.Contains(t, query, "stars:>=10")
+	assert.Contains(t, query, "template:false")
+}
+
+func TestProvider_NextWaitsForRateLimitReset(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Limit", "30")
+		w.Header().Set("X-RateLimit-Resource", "search")
+		if calls == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Second).Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "29")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+		_ = json.NewEncoder(w).Encode(searchResult(1, 1))
+	})
+
+	p := githubProvider(t, mux, config.Config{PushedSince: time.Now().UTC().Add(-24 * time.Hour)}, testutil.MemCache{})
+	p.Rates = provider.NewRateTracker()
+
+	c, err := p.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, 2, calls)
+
+	rate := p.Rates.Snapshot()["search"]
+	assert.Equal(t, 29, rate.Remaining)
+	assert.Equal(t, 30, rate.Limit)
+}
+
+func TestProvider_EnrichCachesSocialAccounts(t *testing.T) {
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/owner/social_accounts", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"provider": "generic", "url": "https://example.com"},
+			{"provider": "bluesky", "url": "https://bsky.app/profile/owner.bsky.social"},
+		})
+	})
+
+	cache := testutil.MemCache{}
+	p := githubProvider(t, mux, config.Config{}, cache)
+
+	for range 2 {
+		c := &provider.Content{Author: provider.Author{Login: "owner"}}
+		require.NoError(t, p.Enrich(context.Background(), c))
+		assert.Equal(t, "owner.bsky.social", c.Author.BlueskyHandle)
+	}
+	assert.Equal(t, 1, calls)
+	assert.Contains(t, cache, "social:owner")
+}
+
+func TestProvider_NextTracksStarGrowth(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(searchResult(1, 3))
+	})
+
+	now := time.Now().UTC()
+	week := 7 * 24 * time.Hour
+	cache := testutil.MemCache{"stars:" + now.Add(-week).Format("2006-01-02"): `{"3": 10}`}
+
+	cfg := config.Config{
+		PushedSince: now.Add(-24 * time.Hour),
+		TrendWindow: week,
+		Weights:     config.Weights{StarGrowth: 1},
+	}
+	p := githubProvider(t, mux, cfg, cache)
+	p.Scorer = provider.WeightedScorer(cfg.Weights)
+
+	c, err := p.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, 32, c.StarGrowth)
+	assert.Equal(t, week, c.GrowthWindow)
+	assert.JSONEq(t, `{"3": 42}`, cache["stars:"+now.Format("2006-01-02")].(string))
+}
+
+func TestProvider_NextStarGrowthUsesOldestSnapshotInWindow(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(searchResult(1, 3, 4))
+	})
+
+	now := time.Now().UTC()
+	day := 24 * time.Hour
+	snapshot := func(daysAgo int) string {
+		return "stars:" + now.Add(-time.Duration(daysAgo)*day).Format("2006-01-02")
+	}
+	cache := testutil.MemCache{
+		snapshot(9): `{"4": 1}`, // outside the window
+		snapshot(6): `{"4": 20}`,
+		snapshot(5): `{"3": 30}`,
+		snapshot(2): `{"3": 40, "4": 40}`,
+	}
+
+	cfg := config.Config{
+		PushedSince: now.Add(-day),
+		TrendWindow: 7 * day,
+		Weights:     config.Weights{StarGrowth: 1},
+		PickTop:     true,
+	}
+	p := githubProvider(t, mux, cfg, cache)
+	p.Scorer = provider.WeightedScorer(cfg.Weights)
+
+	c, err := p.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:4", c.Key, "22 stars over 6 days beat 12 over 5")
+	assert.Equal(t, 22, c.StarGrowth)
+	assert.Equal(t, 6*day, c.GrowthWindow)
+	require.NoError(t, p.MarkSeen(context.Background(), c))
+
+	c, err = p.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:3", c.Key)
+	assert.Equal(t, 12, c.StarGrowth)
+	assert.Equal(t, 5*day, c.GrowthWindow)
+}
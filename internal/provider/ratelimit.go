@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+)
+
+const (
+	// Rate limit categories GitHub counts our calls against.
+	rateSearch = "search"
+	rateCore   = "core"
+
+	// maxRateLimitWait caps how long we block on a reset. Search resets every
+	// minute, core every hour; anything longer is left to the next cycle.
+	maxRateLimitWait = 15 * time.Minute
+)
+
+// RateTracker remembers the last rate limit GitHub reported per category.
+// It's shared between the provider and the stats page, so it's safe for
+// concurrent use. A nil tracker ignores all updates.
+type RateTracker struct {
+	mu    sync.Mutex
+	rates map[string]github.Rate
+}
+
+// NewRateTracker returns an empty tracker.
+func NewRateTracker() *RateTracker {
+	return &RateTracker{rates: map[string]github.Rate{}}
+}
+
+// Snapshot returns the last known rate per category.
+func (t *RateTracker) Snapshot() map[string]github.Rate {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return maps.Clone(t.rates)
+}
+
+func (t *RateTracker) observe(category string, r github.Rate) {
+	if t == nil || r.Limit == 0 {
+		return
+	}
+	if r.Resource != "" {
+		category = r.Resource
+	}
+	t.mu.Lock()
+	t.rates[category] = r
+	t.mu.Unlock()
+}
+
+// low reports whether less than a tenth of the category's budget is left.
+func (t *RateTracker) low(category string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	r, ok := t.rates[category]
+	t.mu.Unlock()
+	return ok && r.Remaining < r.Limit/10 && time.Now().Before(r.Reset.Time)
+}
+
+// wait blocks until the category's budget resets if it's used up.
+func (t *RateTracker) wait(ctx context.Context, category string) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	r, ok := t.rates[category]
+	t.mu.Unlock()
+	if !ok || r.Remaining > 0 {
+		return nil
+	}
+	return sleepUntil(ctx, r.Reset.Time)
+}
+
+// withRateLimit runs call, recording the rate limit it reports. When GitHub
+// says we're rate limited, it waits for the reset and tries once more.
+func (p Provider) withRateLimit(ctx context.Context, category string, call func() (*github.Response, error)) error {
+	if err := p.Rates.wait(ctx, category); err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := call()
+		if resp != nil {
+			p.Rates.observe(category, resp.Rate)
+		}
+
+		var (
+			rateErr  *github.RateLimitError
+			abuseErr *github.AbuseRateLimitError
+			until    time.Time
+		)
+		switch {
+		case errors.As(err, &rateErr):
+			p.Rates.observe(category, rateErr.Rate)
+			until = rateErr.Rate.Reset.Time
+		case errors.As(err, &abuseErr):
+			until = time.Now().Add(time.Minute)
+			if d := abuseErr.RetryAfter; d != nil {
+				until = time.Now().Add(*d)
+			}
+		default:
+			return err
+		}
+
+		if attempt > 0 || time.Until(until) > maxRateLimitWait {
+			return err
+		}
+		slog.InfoContext(ctx, "github rate limited, waiting", "category", category, "until", until)
+		if err := sleepUntil(ctx, until); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepUntil sleeps until t, at most maxRateLimitWait, or until ctx is done.
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := min(time.Until(t), maxRateLimitWait)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
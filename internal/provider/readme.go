@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// minDescriptionLength is the length below which a description says too
+// little to post, so the README is consulted instead.
+const minDescriptionLength = 20
+
+// minSummaryWords keeps leftovers like a lone project name or "WIP" from
+// being used as the description.
+const minSummaryWords = 4
+
+var (
+	htmlComment  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	codeFence    = regexp.MustCompile("(?ms)^\\s*(```|~~~).*?^\\s*(```|~~~)\\s*$")
+	setextTitle  = regexp.MustCompile(`(?m)^[^\n]+\n[ \t]*(=+|-+)[ \t]*$`)
+	htmlHeading  = regexp.MustCompile(`(?is)<h[1-6][^>]*>.*?</h[1-6]>`)
+	htmlTag      = regexp.MustCompile(`<[^>]+>`)
+	mdImage      = regexp.MustCompile(`!\[[^\]]*\](\([^)]*\)|\[[^\]]*\])`)
+	mdLink       = regexp.MustCompile(`\[([^\]]*)\](\([^)]*\)|\[[^\]]*\])`)
+	mdRefDef     = regexp.MustCompile(`^\s*\[[^\]]+\]:\s*\S+`)
+	mdEmphasis   = regexp.MustCompile("(\\*\\*|__|\\*|`)")
+	setextMarker = regexp.MustCompile(`^\s*(=+|-+)\s*$`)
+)
+
+// needsDescription reports whether desc is missing or too short to post.
+func needsDescription(desc string) bool {
+	return len(strings.TrimSpace(desc)) < minDescriptionLength
+}
+
+// describe returns repo's description. Under Quality.RequireDescription a
+// missing one is taken from the README right away, and ok is false when
+// the README has none either. Otherwise Enrich fills it in later.
+func (p Provider) describe(ctx context.Context, repo *github.Repository) (desc string, ok bool) {
+	desc = repo.GetDescription()
+	if !p.Config.Quality.RequireDescription || strings.TrimSpace(desc) != "" {
+		return desc, true
+	}
+	if p.Rates.low(rateCore) {
+		return "", false
+	}
+
+	desc, err := p.readmeDescription(ctx, repo.GetFullName())
+	if err != nil {
+		slog.WarnContext(ctx, "readme description failed", "repo", repo.GetFullName(), "err", err)
+	}
+	return desc, desc != ""
+}
+
+// readmeDescription returns the first meaningful paragraph of the repo's
+// README, or "" when there is none.
+func (p Provider) readmeDescription(ctx context.Context, fullName string) (string, error) {
+	owner, name, ok := strings.Cut(fullName, "/")
+	if !ok {
+		return "", nil
+	}
+
+	var readme *github.RepositoryContent
+	err := p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+		readme, resp, err = p.GitHubRepoClient.GetReadme(ctx, owner, name, nil)
+		return resp, err
+	})
+	if isNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("readme of %s: %w", fullName, err)
+	}
+
+	md, err := readme.GetContent()
+	if err != nil {
+		return "", fmt.Errorf("decode readme of %s: %w", fullName, err)
+	}
+	return readmeSummary(md), nil
+}
+
+// readmeSummary extracts the first paragraph of prose from a Markdown
+// README. Headings, badges, images, code blocks, tables and HTML are
+// skipped; links are reduced to their text.
+func readmeSummary(md string) string {
+	md = strings.ReplaceAll(md, "\r\n", "\n")
+	md = htmlComment.ReplaceAllString(md, "")
+	md = codeFence.ReplaceAllString(md, "")
+	md = setextTitle.ReplaceAllString(md, "")
+	md = htmlHeading.ReplaceAllString(md, "")
+
+	for para := range strings.SplitSeq(md, "\n\n") {
+		if s := paragraphText(para); len(strings.Fields(s)) >= minSummaryWords {
+			return s
+		}
+	}
+	return ""
+}
+
+// paragraphText returns the plain text of a Markdown paragraph, or "" when
+// it's a heading, table, or consists of markup only.
+func paragraphText(para string) string {
+	var words []string
+	for line := range strings.Lines(para) {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "",
+			strings.HasPrefix(line, "#"),
+			strings.HasPrefix(line, "|"),
+			setextMarker.MatchString(line),
+			mdRefDef.MatchString(line):
+			continue
+		}
+
+		line = mdImage.ReplaceAllString(line, "")
+		line = htmlTag.ReplaceAllString(line, "")
+		line = mdLink.ReplaceAllString(line, "$1")
+		line = mdEmphasis.ReplaceAllString(line, "")
+		line = strings.TrimLeft(line, ">-+ ")
+		words = append(words, strings.Fields(line)...)
+	}
+
+	s := html.UnescapeString(strings.Join(words, " "))
+	if !strings.ContainsFunc(s, unicode.IsLetter) {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestProvider_EnrichDescriptionFromReadme(t *testing.T) {
+	testCases := []struct {
+		name        string
+		description string
+		readme      string
+		want        string
+	}{
+		{
+			name:        "first paragraph after title and badges",
+			description: "",
+			readme: "# gizmo\n\n" +
+				"[![Go Reference](https://pkg.go.dev/badge/x.svg)](https://pkg.go.dev/x) [![CI](https://x/ci.svg)](https://x/ci)\n\n" +
+				"A **fast** [gizmo](https://gizmo.dev) for\nparsing `things` &amp; stuff.\n\n" +
+				"## Install\n\nRun the installer.\n",
+			want: "A fast gizmo for parsing things & stuff.",
+		},
+		{
+			name:        "html header and setext title",
+			description: "wip",
+			readme: "<p align=\"center\"><img src=\"logo.png\"></p>\n" +
+				"<h1 align=\"center\">Gizmo</h1>\n\n" +
+				"Gizmo\n=====\n\n" +
+				"<!-- a comment with enough words in it -->\n" +
+				"```go\nfmt.Println(\"code is not a description\")\n```\n\n" +
+				"Gizmo turns markdown into plain text.\n",
+			want: "Gizmo turns markdown into plain text.",
+		},
+		{
+			name:        "badge only",
+			description: "",
+			readme:      "[![CI][ci-badge]][ci]\n\n[ci-badge]: https://x/ci.svg\n[ci]: https://x/ci\n",
+			want:        "",
+		},
+		{
+			name:        "heading only",
+			description: "",
+			readme:      "# gizmo\n\n## Usage\n\n## License\n",
+			want:        "",
+		},
+		{
+			name:        "description good enough",
+			description: "A perfectly fine description",
+			readme:      "Not consulted at all, the description is long enough.",
+			want:        "A perfectly fine description",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/gizmo/readme", func(w http.ResponseWriter, _ *http.Request) {
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"encoding": "base64",
+					"content":  base64.StdEncoding.EncodeToString([]byte(tc.readme)),
+				})
+			})
+			p := githubProvider(t, mux, config.Config{}, testutil.MemCache{})
+
+			c := &provider.Content{FullName: "owner/gizmo", Description: tc.description}
+			require.NoError(t, p.Enrich(context.Background(), c))
+			assert.Equal(t, tc.want, c.Description)
+		})
+	}
+}
+
+func TestProvider_NextRequiredDescriptionFromReadme(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		// neither repo has a description, only repo2 has a README to stand in
+		res := searchResult(2, 1, 2)
+		for _, item := range res["items"].([]map[string]any) {
+			item["full_name"] = "owner/" + item["name"].(string)
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	mux.HandleFunc("/repos/owner/repo2/readme", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte("# repo2\n\nA small tool that does useful things.\n")),
+		})
+	})
+
+	// require-description is on by default
+	cfg := config.Config{
+		PushedSince: time.Now().Add(-24 * time.Hour),
+		Quality:     config.Quality{RequireDescription: true},
+	}
+	p := githubProvider(t, mux, cfg, testutil.MemCache{})
+
+	c, err := provider.GetContentToPublish(context.Background(), p)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:2", c.Key)
+	assert.Equal(t, "A small tool that does useful things.", c.Description)
+
+	// repo1 has no README paragraph either
+	c, err = provider.GetContentToPublish(context.Background(), p)
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
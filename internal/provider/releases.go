@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/go-github/v90/github"
+	"github.com/till/golangoss-bluesky/internal/config"
+)
+
+const (
+	// featuredKey holds the repos we've posted about, most recent first.
+	featuredKey = "featured"
+	// featuredLimit caps the featured list, and with it the number of
+	// release lookups per check.
+	featuredLimit = 200
+	// featuredTTL keeps the list around as long as the seen keys.
+	featuredTTL = 60 * 24 * time.Hour
+
+	// releaseMaxAge is how old a release may be and still get announced. It
+	// keeps the first check of a newly watched repo from posting a release
+	// that's been out for months.
+	releaseMaxAge = 7 * 24 * time.Hour
+)
+
+// Release is a GitHub release worth announcing.
+type Release struct {
+	Key         string // cache key, "release:<id>"
+	Repo        string // "owner/name"
+	Tag         string
+	Name        string
+	Notes       string // release body, markdown
+	URL         string
+	PublishedAt time.Time
+	// Hashtag is the campaign's lead tag, e.g. "#go", empty when it has
+	// none.
+	Hashtag string
+}
+
+func releaseKey(id int64) string {
+	return fmt.Sprintf("release:%d", id)
+}
+
+// recordFeatured puts fullName at the top of the featured list.
+func (p Provider) recordFeatured(ctx context.Context, fullName string) error {
+	if fullName == "" {
+		return nil
+	}
+	repos, err := p.FeaturedRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	repos = slices.DeleteFunc(repos, func(r string) bool { return strings.EqualFold(r, fullName) })
+	repos = append([]string{fullName}, repos...)
+	if len(repos) > featuredLimit {
+		repos = repos[:featuredLimit]
+	}
+	return p.saveFeatured(ctx, repos)
+}
+
+// ForgetFeatured takes fullName off the featured list, e.g. when its post
+// was turned down by a moderator, so its releases aren't announced.
+func (p Provider) ForgetFeatured(ctx context.Context, fullName string) error {
+	repos, err := p.FeaturedRepos(ctx)
+	if err != nil {
+		return err
+	}
+	n := len(repos)
+	repos = slices.DeleteFunc(repos, func(r string) bool { return strings.EqualFold(r, fullName) })
+	if len(repos) == n {
+		return nil
+	}
+	return p.saveFeatured(ctx, repos)
+}
+
+func (p Provider) saveFeatured(ctx context.Context, repos []string) error {
+	raw, err := json.Marshal(repos)
+	if err != nil {
+		return err
+	}
+	return p.CacheClient.Set(ctx, featuredKey, string(raw), featuredTTL)
+}
+
+// FeaturedRepos returns the GitHub repos we've posted about, most recent
+// first, as "owner/name".
+func (p Provider) FeaturedRepos(ctx context.Context) ([]string, error) {
+	raw, err := p.CacheClient.Get(ctx, featuredKey)
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load featured repos: %w", err)
+	}
+
+	var repos []string
+	if err := json.Unmarshal([]byte(raw), &repos); err != nil {
+		slog.WarnContext(ctx, "featured list invalid, starting over", "err", err)
+		return nil, nil
+	}
+	return repos, nil
+}
+
+// NewReleases returns the latest release of each repo ("owner/name") when
+// it's recent and hasn't been announced yet. Drafts and pre-releases are
+// skipped. When the API budget runs low, it stops early and returns what it
+// found so far; the rest is picked up by the next check.
+func (p Provider) NewReleases(ctx context.Context, repos []string) ([]*Release, error) {
+	var out []*Release
+	for _, fullName := range repos {
+		if p.Rates.low(rateCore) {
+			slog.InfoContext(ctx, "github budget low, postponing release checks", "left", len(repos))
+			break
+		}
+
+		owner, name, ok := strings.Cut(fullName, "/")
+		if !ok || owner == "" || name == "" {
+			slog.WarnContext(ctx, "invalid repo, expected owner/name", "repo", fullName)
+			continue
+		}
+
+		var rel *github.RepositoryRelease
+		err := p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+			rel, resp, err = p.GitHubRepoClient.GetLatestRelease(ctx, owner, name)
+			return resp, err
+		})
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return out, fmt.Errorf("latest release of %s: %w", fullName, err)
+		}
+		if rel.GetID() == 0 || rel.GetDraft() || rel.GetPrerelease() {
+			continue
+		}
+		if time.Since(rel.GetPublishedAt().Time) > releaseMaxAge {
+			continue
+		}
+
+		key := releaseKey(rel.GetID())
+		seen, err := isSeen(ctx, p.CacheClient, key)
+		if err != nil {
+			return out, err
+		}
+		if seen {
+			continue
+		}
+
+		out = append(out, &Release{
+			Key:         key,
+			Repo:        fullName,
+			Tag:         rel.GetTagName(),
+			Name:        rel.GetName(),
+			Notes:       rel.GetBody(),
+			URL:         rel.GetHTMLURL(),
+			PublishedAt: rel.GetPublishedAt().Time,
+			Hashtag:     releaseHashtag(p.Config),
+		})
+	}
+	return out, nil
+}
+
+// releaseHashtag returns the hashtag of a release post: the lead tag of
+// the repo posts, without topics, as the release isn't looked up with its
+// repo.
+func releaseHashtag(cfg config.Config) string {
+	if lead := leadTag(cfg); lead != "" {
+		return "#" + lead
+	}
+	return ""
+}
+
+// MarkReleaseSeen caches the release's key so it's announced only once.
+func (p Provider) MarkReleaseSeen(ctx context.Context, r *Release) error {
+	return markSeen(ctx, p.CacheClient, r.Key)
+}
+
+// isNotFound reports whether err is GitHub's 404, e.g. for a repo without
+// any releases.
+func isNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,122 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func release(id int64, tag string, published time.Time) map[string]any {
+	return map[string]any{
+		"id":           id,
+		"tag_name":     tag,
+		"name":         "Release " + tag,
+		"body":         "- fixes",
+		"html_url":     "https://github.com/owner/repo/releases/tag/" + tag,
+		"published_at": published.Format(time.RFC3339),
+	}
+}
+
+func TestProvider_NewReleases(t *testing.T) {
+	now := time.Now()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/fresh/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(release(1, "v1.1.0", now.Add(-time.Hour)))
+	})
+	mux.HandleFunc("/repos/owner/announced/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(release(2, "v2.0.0", now.Add(-time.Hour)))
+	})
+	mux.HandleFunc("/repos/owner/stale/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(release(3, "v0.1.0", now.Add(-90*24*time.Hour)))
+	})
+	mux.HandleFunc("/repos/owner/none/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+	})
+
+	cache := testutil.MemCache{"release:2": true}
+	p := githubProvider(t, mux, config.Config{}, cache)
+
+	ctx := context.Background()
+	releases, err := p.NewReleases(ctx, []string{"owner/fresh", "owner/announced", "owner/stale", "owner/none", "invalid"})
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+
+	r := releases[0]
+	assert.Equal(t, "release:1", r.Key)
+	assert.Equal(t, "owner/fresh", r.Repo)
+	assert.Equal(t, "v1.1.0", r.Tag)
+	assert.Equal(t, "Release v1.1.0", r.Name)
+	assert.Equal(t, "https://github.com/owner/repo/releases/tag/v1.1.0", r.URL)
+	assert.Empty(t, r.Hashtag, "no language, no tag")
+
+	require.NoError(t, p.MarkReleaseSeen(ctx, r))
+	releases, err = p.NewReleases(ctx, []string{"owner/fresh"})
+	require.NoError(t, err)
+	assert.Empty(t, releases)
+}
+
+func TestProvider_NewReleasesHashtag(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  config.Config
+		want string
+	}{
+		{"language", config.Config{Language: "Go"}, "#go"},
+		{"campaign tag", config.Config{Language: "go", Hashtags: config.Hashtags{Lead: "#templ"}}, "#templ"},
+		{"topic only", config.Config{Topic: "webassembly"}, ""},
+		{"invalid language tag", config.Config{Language: "C#"}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/fresh/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
+				_ = json.NewEncoder(w).Encode(release(1, "v1.1.0", time.Now().Add(-time.Hour)))
+			})
+			p := githubProvider(t, mux, tc.cfg, testutil.MemCache{})
+
+			releases, err := p.NewReleases(context.Background(), []string{"owner/fresh"})
+			require.NoError(t, err)
+			require.Len(t, releases, 1)
+			assert.Equal(t, tc.want, releases[0].Hashtag)
+		})
+	}
+}
+
+func TestProvider_MarkSeenRecordsFeatured(t *testing.T) {
+	cache := testutil.MemCache{}
+	p := githubProvider(t, http.NewServeMux(), config.Config{}, cache)
+
+	ctx := context.Background()
+	for _, name := range []string{"owner/a", "owner/b", "Owner/A"} {
+		require.NoError(t, p.MarkSeen(ctx, &provider.Content{Key: "repo:" + name, FullName: name}))
+	}
+
+	featured, err := p.FeaturedRepos(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Owner/A", "owner/b"}, featured)
+}
+
+func TestProvider_ForgetFeatured(t *testing.T) {
+	cache := testutil.MemCache{}
+	p := githubProvider(t, http.NewServeMux(), config.Config{}, cache)
+
+	ctx := context.Background()
+	for _, name := range []string{"owner/a", "owner/b"} {
+		require.NoError(t, p.MarkSeen(ctx, &provider.Content{Key: "repo:" + name, FullName: name}))
+	}
+	require.NoError(t, p.ForgetFeatured(ctx, "Owner/B"))
+	require.NoError(t, p.ForgetFeatured(ctx, "owner/unknown"))
+
+	featured, err := p.FeaturedRepos(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"owner/a"}, featured)
+}
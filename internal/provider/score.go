@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/till/golangoss-bluesky/internal/config"
+)
+
+// Score is a candidate's total plus the weighted contribution of each
+// signal, so the breakdown can be logged when tuning weights.
+type Score struct {
+	Total float64
+	Parts map[string]float64
+}
+
+// Scorer rates a candidate, higher is better. Kept as a function type so
+// the ranking can be swapped without touching the provider.
+type Scorer func(repo *github.Repository, now time.Time) Score
+
+// WeightedScorer scores repos as the weighted sum of normalized signals.
+func WeightedScorer(w config.Weights) Scorer {
+	return func(repo *github.Repository, now time.Time) Score {
+		s := Score{Parts: map[string]float64{}}
+		add := func(name string, weight, value float64) {
+			if weight == 0 {
+				return
+			}
+			s.Parts[name] = weight * value
+			s.Total += weight * value
+		}
+
+		add("stars", w.Stars, logScale(repo.GetStargazersCount(), 4))
+		add("forks", w.Forks, logScale(repo.GetForksCount(), 3))
+		add("description", w.Description, math.Min(float64(len(repo.GetDescription()))/100, 1))
+		add("license", w.License, boolScore(hasLicense(repo)))
+		add("topics", w.Topics, math.Min(float64(len(repo.Topics))/5, 1))
+		add("recent_push", w.RecentPush, recency(repo.GetPushedAt().Time, now))
+		add("issues", w.Issues, logScale(repo.GetOpenIssuesCount(), 2))
+
+		return s
+	}
+}
+
+// logScale maps n to [0, 1] so that 10^decades and above score 1.
+func logScale(n int, decades float64) float64 {
+	if n <= 0 {
+		return 0
+	}
+	return math.Min(math.Log10(float64(n)+1)/decades, 1)
+}
+
+// recency is 1 for a push right now, falling linearly to 0 after a year.
+func recency(pushed, now time.Time) float64 {
+	if pushed.IsZero() {
+		return 0
+	}
+	const year = 365 * 24 * time.Hour
+	return math.Max(0, 1-float64(now.Sub(pushed))/float64(year))
+}
+
+func hasLicense(repo *github.Repository) bool {
+	key := repo.GetLicense().GetKey()
+	return key != "" && key != "other"
+}
+
+func boolScore(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// rankByScore returns the indexes of scores in the order candidates should
+// be tried: best first when top is set, otherwise sampled proportionally to
+// score without replacement. If every score is zero the order is uniform.
+func rankByScore(scores []Score, top bool) []int {
+	idx := make([]int, len(scores))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	if top {
+		sort.SliceStable(idx, func(a, b int) bool {
+			return scores[idx[a]].Total > scores[idx[b]].Total
+		})
+		return idx
+	}
+
+	out := make([]int, 0, len(idx))
+	for len(idx) > 0 {
+		total := 0.0
+		for _, i := range idx {
+			total += math.Max(scores[i].Total, 0)
+		}
+
+		pos := rand.IntN(len(idx))
+		if total > 0 {
+			n := rand.Float64() * total
+			for p, i := range idx {
+				n -= math.Max(scores[i].Total, 0)
+				if n < 0 {
+					pos = p
+					break
+				}
+			}
+		}
+
+		out = append(out, idx[pos])
+		idx = append(idx[:pos], idx[pos+1:]...)
+	}
+	return out
+}
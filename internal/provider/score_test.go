@@ -0,0 +1,86 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestWeightedScorer(t *testing.T) {
+	now := time.Now()
+	score := provider.WeightedScorer(config.DefaultWeights())
+
+	good := &github.Repository{
+		StargazersCount: github.Ptr(5000),
+		ForksCount:      github.Ptr(300),
+		Description:     github.Ptr("A fast, well tested library for parsing things that are hard to parse, with batteries included."),
+		License:         &github.License{Key: github.Ptr("mit")},
+		Topics:          []string{"go", "parser", "library"},
+		PushedAt:        &github.Timestamp{Time: now.Add(-24 * time.Hour)},
+		OpenIssuesCount: github.Ptr(12),
+	}
+	bare := &github.Repository{
+		StargazersCount: github.Ptr(1),
+		PushedAt:        &github.Timestamp{Time: now.Add(-300 * 24 * time.Hour)},
+	}
+
+	g, b := score(good, now), score(bare, now)
+	assert.Greater(t, g.Total, b.Total)
+	assert.Equal(t, 1.0, g.Parts["license"])
+	assert.Zero(t, b.Parts["license"])
+	assert.Zero(t, b.Parts["description"])
+	assert.InDelta(t, 1.0, g.Parts["stars"], 0.1)
+}
+
+func TestWeightedScorer_ZeroWeightDisablesSignal(t *testing.T) {
+	score := provider.WeightedScorer(config.Weights{Stars: 1})
+	s := score(&github.Repository{
+		StargazersCount: github.Ptr(100),
+		Description:     github.Ptr("described"),
+	}, time.Now())
+
+	assert.Len(t, s.Parts, 1)
+	assert.Equal(t, s.Parts["stars"], s.Total)
+}
+
+func TestProvider_WeightsChangePickOrder(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		res := searchResult(2, 1, 2)
+		items := res["items"].([]map[string]any)
+		items[0]["stargazers_count"], items[0]["forks_count"] = 5000, 0
+		items[1]["stargazers_count"], items[1]["forks_count"] = 1, 900
+		_ = json.NewEncoder(w).Encode(res)
+	})
+
+	tests := []struct {
+		name    string
+		weights config.Weights
+		want    string
+	}{
+		{"stars", config.Weights{Stars: 1}, "repo:1"},
+		{"forks", config.Weights{Forks: 1}, "repo:2"},
+		{"forks outweigh stars", config.Weights{Stars: 0.1, Forks: 2}, "repo:2"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.Config{Language: "go", PushedSince: time.Now().Add(-24 * time.Hour), Weights: tc.weights, PickTop: true}
+			p := githubProvider(t, mux, cfg, testutil.MemCache{})
+			p.Scorer = provider.WeightedScorer(cfg.Weights)
+
+			c, err := p.Next(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			assert.Equal(t, tc.want, c.Key)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	searchPerPage = 100
+	// searchMaxResults is the most results GitHub returns for one query,
+	// no matter how many pages there are.
+	searchMaxResults = 1000
+	// searchWindowSize is the width of the pushed: range one query covers.
+	// Splitting the active period into windows keeps each query under
+	// searchMaxResults and lets us reach repos beyond the first 1000.
+	searchWindowSize = 30 * 24 * time.Hour
+	// searchPagesPerCycle caps the search calls one Next makes.
+	searchPagesPerCycle = 3
+
+	searchCursorKey = "cursor:github-search"
+)
+
+// window is a pushed: date range, most recent first.
+type window struct {
+	From time.Time
+	To   time.Time
+}
+
+// searchWindows splits [since, now] into windows of searchWindowSize,
+// starting with the most recent one.
+func searchWindows(since, now time.Time) []window {
+	if !since.Before(now) {
+		return []window{{From: since, To: since}}
+	}
+
+	var out []window
+	for to := now; to.After(since); to = to.Add(-searchWindowSize) {
+		from := to.Add(-searchWindowSize)
+		if from.Before(since) {
+			from = since
+		}
+		out = append(out, window{From: from, To: to})
+	}
+	return out
+}
+
+// searchCursor is where the next search picks up. It's persisted in the
+// cache so a restart doesn't send us back to the first page.
+type searchCursor struct {
+	Window int `json:"window"` // index into searchWindows, 0 is the most recent
+	Page   int `json:"page"`   // 1-based
+}
+
+// advance moves past the current page, or on to the next window once the
+// current one has no more results.
+func (c searchCursor) advance(total int) searchCursor {
+	if c.Page*searchPerPage >= min(total, searchMaxResults) {
+		return searchCursor{Window: c.Window + 1, Page: 1}
+	}
+	return searchCursor{Window: c.Window, Page: c.Page + 1}
+}
+
+// loadCursor returns the persisted cursor, or the first page of the most
+// recent window when there is none.
+func (p Provider) loadCursor(ctx context.Context) searchCursor {
+	var cur searchCursor
+
+	raw, err := p.CacheClient.Get(ctx, searchCursorKey)
+	if err == redis.Nil {
+		return cur
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "search cursor load failed", "err", err)
+		return cur
+	}
+	if err := json.Unmarshal([]byte(raw), &cur); err != nil {
+		slog.WarnContext(ctx, "search cursor invalid", "err", err)
+		return searchCursor{}
+	}
+	return cur
+}
+
+// saveCursor persists the cursor. Failures are logged only: the worst case
+// is re-reading pages we've already seen.
+func (p Provider) saveCursor(ctx context.Context, cur searchCursor) {
+	raw, err := json.Marshal(cur)
+	if err != nil {
+		slog.WarnContext(ctx, "search cursor encode failed", "err", err)
+		return
+	}
+	if err := p.CacheClient.Set(ctx, searchCursorKey, string(raw), 0); err != nil {
+		slog.WarnContext(ctx, "search cursor save failed", "err", err)
+	}
+}
+
+// pageCursor is the page the listing of another forge picks up from,
+// persisted like searchCursor.
+type pageCursor struct {
+	Page int `json:"page"` // 1-based
+}
+
+// loadPageCursor returns the cursor stored under key, or the first page
+// when there is none.
+func loadPageCursor(ctx context.Context, cc Cache, key string) pageCursor {
+	cur := pageCursor{Page: 1}
+
+	raw, err := cc.Get(ctx, key)
+	if err == redis.Nil {
+		return cur
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "page cursor load failed", "key", key, "err", err)
+		return cur
+	}
+	if err := json.Unmarshal([]byte(raw), &cur); err != nil || cur.Page < 1 {
+		slog.WarnContext(ctx, "page cursor invalid", "key", key, "err", err)
+		return pageCursor{Page: 1}
+	}
+	return cur
+}
+
+// savePageCursor persists cur under key. Failures are logged only, as in
+// saveCursor.
+func savePageCursor(ctx context.Context, cc Cache, key string, cur pageCursor) {
+	raw, err := json.Marshal(cur)
+	if err != nil {
+		slog.WarnContext(ctx, "page cursor encode failed", "key", key, "err", err)
+		return
+	}
+	if err := cc.Set(ctx, key, string(raw), 0); err != nil {
+		slog.WarnContext(ctx, "page cursor save failed", "key", key, "err", err)
+	}
+}
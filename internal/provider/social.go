@@ -0,0 +1,120 @@
+package provider
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// socialAccount is a profile link of a GitHub user. Provider is what GitHub
+// reports ("bluesky", "mastodon", "generic", ...), or "blog" for the
+// website field of the profile.
+type socialAccount struct {
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+}
+
+var (
+	// domainName has at least two labels. Bluesky handles are domain names,
+	// for bsky.social as well as custom-domain handles.
+	domainName = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+	blueskyDID = regexp.MustCompile(`^did:(plc:[a-z2-7]{24}|web:[a-zA-Z0-9.-]+)$`)
+	// fediverseUser is the user part of @user@instance.
+	fediverseUser = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*$`)
+)
+
+// notFediverse are hosts with /@user profile URLs that aren't fediverse
+// instances.
+var notFediverse = map[string]bool{
+	"medium.com":      true,
+	"youtube.com":     true,
+	"www.youtube.com": true,
+	"tiktok.com":      true,
+	"www.tiktok.com":  true,
+	"threads.net":     true,
+	"www.threads.net": true,
+}
+
+// addAccounts fills in the accounts a's social links point to. The first
+// link found for a network wins.
+func (a *Author) addAccounts(accounts []socialAccount) {
+	for _, sa := range accounts {
+		if a.BlueskyHandle == "" && a.BlueskyDID == "" {
+			a.BlueskyHandle, a.BlueskyDID = parseBlueskyProfile(sa.URL)
+		}
+		if a.Fediverse == "" {
+			a.Fediverse, a.FediverseURL = parseFediverseProfile(sa.URL, sa.Provider == "mastodon")
+		}
+	}
+}
+
+// parseBlueskyProfile returns the handle or DID of a bsky.app profile URL or
+// an at:// URI, or empty strings if raw is neither.
+func parseBlueskyProfile(raw string) (handle, did string) {
+	var id string
+	switch {
+	case strings.HasPrefix(raw, "at://"):
+		id, _, _ = strings.Cut(strings.TrimPrefix(raw, "at://"), "/")
+	default:
+		u, err := url.Parse(raw)
+		if err != nil || (u.Host != "bsky.app" && u.Host != "www.bsky.app") {
+			return "", ""
+		}
+		rest, ok := strings.CutPrefix(strings.TrimSuffix(u.Path, "/"), "/profile/")
+		if !ok || strings.Contains(rest, "/") {
+			return "", ""
+		}
+		id = rest
+	}
+
+	id = strings.TrimPrefix(id, "@")
+	switch {
+	case blueskyDID.MatchString(id):
+		return "", id
+	case domainName.MatchString(id):
+		return strings.ToLower(id), ""
+	}
+	return "", ""
+}
+
+// parseFediverseProfile returns "@user@instance" and the profile URL for
+// Mastodon-style profile links (https://instance/@user) and for plain
+// "@user@instance" addresses. /users/<user> links are only taken from
+// accounts GitHub already knows to be on Mastodon, elsewhere the path is too
+// common to mean anything.
+func parseFediverseProfile(raw string, mastodon bool) (account, profileURL string) {
+	raw = strings.TrimSpace(raw)
+
+	if addr, ok := strings.CutPrefix(raw, "@"); ok {
+		user, host, _ := strings.Cut(addr, "@")
+		if !fediverseUser.MatchString(user) || !domainName.MatchString(host) {
+			return "", ""
+		}
+		host = strings.ToLower(host)
+		return "@" + user + "@" + host, "https://" + host + "/@" + user
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Host == "" || notFediverse[strings.ToLower(u.Host)] {
+		return "", ""
+	}
+	path := strings.Trim(u.Path, "/")
+	user, ok := strings.CutPrefix(path, "@")
+	if !ok && mastodon {
+		user, ok = strings.CutPrefix(path, "users/")
+	}
+	if !ok {
+		return "", ""
+	}
+
+	// /@user@otherinstance points to a remote account, which is the one we
+	// want
+	name, host, remote := strings.Cut(user, "@")
+	if !remote {
+		host = u.Hostname()
+	}
+	if !fediverseUser.MatchString(name) || !domainName.MatchString(host) {
+		return "", ""
+	}
+	return "@" + name + "@" + strings.ToLower(host), raw
+}
@@ -0,0 +1,95 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestProvider_EnrichDiscoversAccounts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		accounts []map[string]any
+		blog     string
+		want     provider.Author
+	}{
+		{
+			name:     "bsky.social handle",
+			accounts: []map[string]any{{"provider": "bluesky", "url": "https://bsky.app/profile/Owner.bsky.social"}},
+			want:     provider.Author{BlueskyHandle: "owner.bsky.social"},
+		},
+		{
+			name:     "custom domain handle",
+			accounts: []map[string]any{{"provider": "bluesky", "url": "https://bsky.app/profile/owner.dev/"}},
+			want:     provider.Author{BlueskyHandle: "owner.dev"},
+		},
+		{
+			name:     "did:plc profile",
+			accounts: []map[string]any{{"provider": "bluesky", "url": "https://bsky.app/profile/did:plc:ewvi7nxzyoun6zhxrhs64oiz"}},
+			want:     provider.Author{BlueskyDID: "did:plc:ewvi7nxzyoun6zhxrhs64oiz"},
+		},
+		{
+			name:     "at uri on the website field",
+			blog:     "at://owner.example.org",
+			accounts: []map[string]any{},
+			want:     provider.Author{BlueskyHandle: "owner.example.org"},
+		},
+		{
+			name: "mastodon",
+			accounts: []map[string]any{
+				{"provider": "generic", "url": "https://example.com"},
+				{"provider": "mastodon", "url": "https://hachyderm.io/@owner"},
+			},
+			want: provider.Author{Fediverse: "@owner@hachyderm.io", FediverseURL: "https://hachyderm.io/@owner"},
+		},
+		{
+			name:     "fediverse address on the website field",
+			accounts: []map[string]any{{"provider": "generic", "url": "https://medium.com/@owner"}},
+			blog:     "@owner@fosstodon.org",
+			want:     provider.Author{Fediverse: "@owner@fosstodon.org", FediverseURL: "https://fosstodon.org/@owner"},
+		},
+		{
+			name:     "users path only for mastodon accounts",
+			accounts: []map[string]any{{"provider": "generic", "url": "https://forum.example.com/users/owner"}},
+			want:     provider.Author{},
+		},
+		{
+			name: "both networks",
+			accounts: []map[string]any{
+				{"provider": "mastodon", "url": "https://social.example/users/owner"},
+				{"provider": "bluesky", "url": "https://bsky.app/profile/owner.bsky.social"},
+			},
+			want: provider.Author{
+				BlueskyHandle: "owner.bsky.social",
+				Fediverse:     "@owner@social.example",
+				FediverseURL:  "https://social.example/users/owner",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/users/owner/social_accounts", func(w http.ResponseWriter, _ *http.Request) {
+				_ = json.NewEncoder(w).Encode(tc.accounts)
+			})
+			mux.HandleFunc("/users/owner", func(w http.ResponseWriter, _ *http.Request) {
+				_ = json.NewEncoder(w).Encode(map[string]any{"login": "owner", "blog": tc.blog})
+			})
+			p := githubProvider(t, mux, config.Config{}, testutil.MemCache{})
+
+			c := &provider.Content{Description: "long enough to skip the readme", Author: provider.Author{Login: "owner"}}
+			require.NoError(t, p.Enrich(context.Background(), c))
+
+			tc.want.Login = "owner"
+			assert.Equal(t, tc.want, c.Author)
+		})
+	}
+}
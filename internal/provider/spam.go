@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/go-github/v90/github"
+	"github.com/till/golangoss-bluesky/internal/spam"
+)
+
+// spamRejectTTL is how long a repo classified as spam is skipped without
+// classifying it again.
+const spamRejectTTL = 30 * 24 * time.Hour
+
+func rejectedKey(key string) string {
+	return "rejected:" + key
+}
+
+// classify runs the spam classifier on repo and returns why it's spam, or ""
+// when it isn't. The owner's account age is only looked up when it could
+// make the difference. Spam is recorded in the cache with its reasons, so
+// later cycles skip the repo without classifying it again.
+func (p Provider) classify(ctx context.Context, repo *github.Repository) (string, error) {
+	cl := p.Config.Spam
+	if !cl.Enabled() {
+		return "", nil
+	}
+
+	key := rejectedKey(repoKey(repo.GetID()))
+	reason, err := p.CacheClient.Get(ctx, key)
+	if err == nil {
+		return reason, nil
+	}
+	if err != redis.Nil {
+		return "", err
+	}
+
+	now := time.Now()
+	r := spamRepo(repo)
+	v := cl.Classify(r, now)
+	if cl.NeedsOwnerAge(v) && !p.Rates.low(rateCore) {
+		created, err := p.ownerCreated(ctx, repo.GetOwner())
+		if err != nil {
+			slog.WarnContext(ctx, "owner lookup failed", "login", repo.GetOwner().GetLogin(), "err", err)
+		} else {
+			r.OwnerCreated = created
+			v = cl.Classify(r, now)
+		}
+	}
+	if !v.Spam {
+		return "", nil
+	}
+
+	reason = "spam: " + v.Reason()
+	if err := p.CacheClient.Set(ctx, key, reason, spamRejectTTL); err != nil {
+		slog.WarnContext(ctx, "recording spam failed", "repo", repo.GetFullName(), "err", err)
+	}
+	return reason, nil
+}
+
+// ownerCreated returns when owner's account was created. Search results
+// don't include it, so it's usually fetched.
+func (p Provider) ownerCreated(ctx context.Context, owner *github.User) (time.Time, error) {
+	if owner.CreatedAt != nil {
+		return owner.GetCreatedAt().Time, nil
+	}
+
+	var user *github.User
+	err := p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+		user, resp, err = p.GitHubUserClient.Get(ctx, owner.GetLogin())
+		return resp, err
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return user.GetCreatedAt().Time, nil
+}
+
+func spamRepo(repo *github.Repository) spam.Repo {
+	return spam.Repo{
+		Name:        repo.GetName(),
+		Description: repo.GetDescription(),
+		Topics:      repo.Topics,
+	}
+}
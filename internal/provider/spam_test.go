@@ -0,0 +1,59 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v90/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/spam"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestProvider_NextRejectsSpam(t *testing.T) {
+	ownerLookups := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		res := searchResult(3, 1, 2, 3)
+		items := res["items"].([]map[string]any)
+		items[0]["description"] = "Solana wallet drainer"
+		items[1]["description"] = "homework for my go course"
+		items[2]["description"] = "a clean little library"
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	mux.HandleFunc("/users/owner", func(w http.ResponseWriter, _ *http.Request) {
+		ownerLookups++
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"login":      "owner",
+			"created_at": time.Now().Add(-48 * time.Hour).Format(time.RFC3339),
+		})
+	})
+
+	cl, err := spam.New(spam.DefaultRules())
+	require.NoError(t, err)
+	cfg := config.Config{PushedSince: time.Now().Add(-24 * time.Hour), Spam: cl, PickTop: true}
+
+	cache := testutil.MemCache{}
+	p := githubProvider(t, mux, cfg, cache)
+	p.Scorer = func(repo *github.Repository, _ time.Time) provider.Score {
+		// rank in search order so every candidate is classified
+		return provider.Score{Total: -float64(repo.GetID()), Parts: map[string]float64{}}
+	}
+
+	for range 2 {
+		c, err := p.Next(context.Background())
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, "repo:3", c.Key)
+	}
+
+	assert.Equal(t, "spam: crypto drainer", cache["rejected:repo:1"])
+	assert.Equal(t, "spam: homework, owner younger than 30 days", cache["rejected:repo:2"])
+	assert.Equal(t, 1, ownerLookups, "rejections are cached")
+}
@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/go-github/v90/github"
+)
+
+// submissionsPerCycle caps the submissions looked up per Next, so a long
+// queue doesn't eat the API budget of a single cycle.
+const submissionsPerCycle = 5
+
+var _ Source = Submissions{}
+
+// Submissions hands out the repos queued in a Provider's Submissions,
+// checked like its search results. It's asked ahead of the weighted
+// rotation, so submissions don't wait for the GitHub provider's turn.
+type Submissions struct {
+	p Provider
+}
+
+// NewSubmissions creates a source of p's queued submissions.
+func NewSubmissions(p Provider) Submissions {
+	return Submissions{p: p}
+}
+
+// Next returns the first queued submission that passes, see nextSubmission.
+func (s Submissions) Next(ctx context.Context) (*Content, error) {
+	return s.p.nextSubmission(ctx)
+}
+
+// Enrich is the GitHub provider's.
+func (s Submissions) Enrich(ctx context.Context, c *Content) error {
+	return s.p.Enrich(ctx, c)
+}
+
+// MarkSeen is the GitHub provider's.
+func (s Submissions) MarkSeen(ctx context.Context, c *Content) error {
+	return s.p.MarkSeen(ctx, c)
+}
+
+// nextSubmission drains the submission queue, oldest first, and returns the
+// first submitted repo that passes the search filters and the checks of
+// pick. Every submission looked at leaves the queue, also when it's
+// rejected; one whose lookup fails stays for the next cycle.
+func (p Provider) nextSubmission(ctx context.Context) (*Content, error) {
+	if p.Submissions == nil {
+		return nil, nil
+	}
+	subs, err := p.Submissions.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("submissions: %w", err)
+	}
+
+	for i, s := range subs {
+		if i == submissionsPerCycle {
+			break
+		}
+
+		var repo *github.Repository
+		err := p.withRateLimit(ctx, rateCore, func() (resp *github.Response, err error) {
+			repo, resp, err = p.GitHubRepoClient.Get(ctx, s.Owner, s.Name)
+			return resp, err
+		})
+		if err != nil && !isNotFound(err) {
+			return nil, fmt.Errorf("submission %s: %w", s.FullName(), err)
+		}
+		if err := p.Submissions.Remove(ctx, s); err != nil {
+			return nil, fmt.Errorf("submission %s: %w", s.FullName(), err)
+		}
+		if repo == nil {
+			slog.InfoContext(ctx, "submission rejected", "repo", s.FullName(), "reason", "not found")
+			continue
+		}
+		if reason := p.searchReject(repo); reason != "" {
+			slog.InfoContext(ctx, "submission rejected", "repo", s.FullName(), "reason", reason)
+			continue
+		}
+
+		c, err := p.check(ctx, repo)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			slog.InfoContext(ctx, "submission rejected", "repo", s.FullName(), "reason", "filtered or seen")
+			continue
+		}
+		slog.InfoContext(ctx, "picked submission", "repo", s.FullName())
+		return c, nil
+	}
+	return nil, nil
+}
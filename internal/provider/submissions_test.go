@@ -0,0 +1,81 @@
+package provider_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/config"
+	"github.com/till/golangoss-bluesky/internal/provider"
+	"github.com/till/golangoss-bluesky/internal/submission"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+type memQueue []submission.Submission
+
+func (q *memQueue) List(_ context.Context) ([]submission.Submission, error) {
+	return slices.Clone(*q), nil
+}
+
+func (q *memQueue) Remove(_ context.Context, s submission.Submission) error {
+	*q = slices.DeleteFunc(*q, func(o submission.Submission) bool { return o.Key == s.Key })
+	return nil
+}
+
+func TestSubmissions_Next(t *testing.T) {
+	searches := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/repositories", func(w http.ResponseWriter, _ *http.Request) {
+		searches++
+		_ = json.NewEncoder(w).Encode(searchResult(1, 1))
+	})
+	pushed := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	mux.HandleFunc("/repos/owner/archived", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 10, "archived": true, "language": "Go", "pushed_at": pushed})
+	})
+	mux.HandleFunc("/repos/owner/seen", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 11, "language": "Go", "pushed_at": pushed})
+	})
+	mux.HandleFunc("/repos/owner/good", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id": 12, "name": "good", "full_name": "owner/good", "language": "Go", "pushed_at": pushed,
+			"owner": map[string]any{"login": "owner"},
+		})
+	})
+	// owner/gone answers 404
+
+	queue := &memQueue{}
+	for _, name := range []string{"gone", "archived", "seen", "good"} {
+		*queue = append(*queue, submission.Submission{Key: "submission:" + name, Owner: "owner", Name: name})
+	}
+
+	cfg := config.Config{Language: "go", PushedSince: time.Now().Add(-24 * time.Hour)}
+	p := githubProvider(t, mux, cfg, testutil.MemCache{"repo:11": true})
+	p.Submissions = queue
+	subs := provider.NewSubmissions(p)
+
+	c, err := subs.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:12", c.Key)
+	assert.Empty(t, *queue, "rejected submissions leave the queue too")
+
+	c, err = subs.Next(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, c, "nothing left to submit")
+	assert.Zero(t, searches)
+
+	// the search doesn't drain the queue itself
+	*queue = append(*queue, submission.Submission{Key: "submission:good", Owner: "owner", Name: "good"})
+	c, err = p.Next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	assert.Equal(t, "repo:1", c.Key)
+	assert.Equal(t, 1, searches)
+	assert.Len(t, *queue, 1)
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/go-github/v90/github"
+)
+
+const day = 24 * time.Hour
+
+// snapshotRetentionSlack keeps daily snapshots a little longer than the
+// trend window, so the comparison day is still there when we need it.
+const snapshotRetentionSlack = 2 * 24 * time.Hour
+
+// starSnapshots records star counts of every candidate we see, one object
+// per day ("stars:<date>" mapping repo ID to stars), and compares against
+// the oldest snapshot within the trend window that has the repo. Keeping a
+// day per object costs a cache read per day of the window and one write per
+// search page instead of a read and write per repo.
+type starSnapshots struct {
+	cache  Cache
+	window time.Duration
+}
+
+func snapshotKey(day time.Time) string {
+	return "stars:" + day.UTC().Format("2006-01-02")
+}
+
+// record merges the repos' current stars into today's snapshot.
+func (s starSnapshots) record(ctx context.Context, now time.Time, repos []*github.Repository) error {
+	key := snapshotKey(now)
+	day, err := s.load(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		if repo.ID != nil {
+			day[strconv.FormatInt(*repo.ID, 10)] = repo.GetStargazersCount()
+		}
+	}
+
+	raw, err := json.Marshal(day)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, key, string(raw), s.window+snapshotRetentionSlack)
+}
+
+// starTrend is the stars a repo gained since the oldest snapshot of it
+// within the trend window, Over days ago.
+type starTrend struct {
+	Gained int
+	Over   time.Duration
+}
+
+// growth returns the star trend per repo ID, for the repos that were seen
+// on an earlier day within the window. Repos are rarely seen exactly a
+// window apart, so each is compared against the oldest snapshot it's in.
+func (s starSnapshots) growth(ctx context.Context, now time.Time, repos []*github.Repository) (map[int64]starTrend, error) {
+	out := map[int64]starTrend{}
+	for days := int(s.window / day); days >= 1 && len(out) < len(repos); days-- {
+		over := time.Duration(days) * day
+		then, err := s.load(ctx, snapshotKey(now.Add(-over)))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, repo := range repos {
+			if repo.ID == nil {
+				continue
+			}
+			if _, ok := out[*repo.ID]; ok {
+				continue
+			}
+			if old, ok := then[strconv.FormatInt(*repo.ID, 10)]; ok {
+				out[*repo.ID] = starTrend{Gained: repo.GetStargazersCount() - old, Over: over}
+			}
+		}
+	}
+	return out, nil
+}
+
+func (s starSnapshots) load(ctx context.Context, key string) (map[string]int, error) {
+	day := map[string]int{}
+
+	raw, err := s.cache.Get(ctx, key)
+	if err == redis.Nil {
+		return day, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load %s: %w", key, err)
+	}
+	if err := json.Unmarshal([]byte(raw), &day); err != nil {
+		slog.WarnContext(ctx, "star snapshot invalid, starting over", "key", key, "err", err)
+		return map[string]int{}, nil
+	}
+	return day, nil
+}
+
+// starGrowth records this page's star counts and returns each repo's trend
+// within the trend window. Failures are logged and yield no
+// growth data; trending is a ranking signal, not worth failing a cycle for.
+func (p Provider) starGrowth(ctx context.Context, now time.Time, repos []*github.Repository) map[int64]starTrend {
+	if p.Config.TrendWindow <= 0 {
+		return nil
+	}
+
+	s := starSnapshots{cache: p.CacheClient, window: p.Config.TrendWindow}
+	if err := s.record(ctx, now, repos); err != nil {
+		slog.WarnContext(ctx, "star snapshot failed", "err", err)
+	}
+	growth, err := s.growth(ctx, now, repos)
+	if err != nil {
+		slog.WarnContext(ctx, "star growth failed", "err", err)
+		return nil
+	}
+	return growth
+}
@@ -0,0 +1,236 @@
+// Package spam is a rule-based classifier for repos not worth featuring:
+// crypto drainers, follower bots, cheats and homework dumps. Each signal that
+// matches a repo adds its weight to the score; a repo scoring at or above the
+// threshold is spam. It runs offline, on what the search already returned
+// plus the owner's account age when the caller knows it.
+package spam
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Fields a signal can look at.
+const (
+	FieldName        = "name"
+	FieldDescription = "description"
+	FieldTopics      = "topics"
+)
+
+// Rules is the content of the rules file, e.g.
+//
+//	{
+//	  "threshold": 1,
+//	  "signals": [
+//	    {"reason": "crypto drainer", "keywords": ["drainer", "seed phrase"], "weight": 1},
+//	    {"reason": "homework", "patterns": ["^(cs|ece)[0-9]{3}"], "fields": ["name"], "weight": 0.5}
+//	  ],
+//	  "new_owner": {"days": 30, "weight": 0.5}
+//	}
+type Rules struct {
+	Threshold float64  `json:"threshold"`
+	Signals   []Signal `json:"signals"`
+	// NewOwner adds Weight when the owner's account is younger than Days.
+	NewOwner struct {
+		Days   int     `json:"days"`
+		Weight float64 `json:"weight"`
+	} `json:"new_owner"`
+}
+
+// Signal matches keywords (case-insensitive, on word boundaries) or regular
+// expressions against the listed fields, all of them when Fields is empty.
+type Signal struct {
+	Reason   string   `json:"reason"`
+	Keywords []string `json:"keywords"`
+	Patterns []string `json:"patterns"`
+	Fields   []string `json:"fields"`
+	Weight   float64  `json:"weight"`
+}
+
+// Repo is what the classifier looks at.
+type Repo struct {
+	Name        string
+	Description string
+	Topics      []string
+	// OwnerCreated is when the owner's account was created, zero if unknown.
+	OwnerCreated time.Time
+}
+
+// Verdict is the outcome of classifying a repo.
+type Verdict struct {
+	Score   float64
+	Reasons []string // reasons of the matching signals
+	Spam    bool
+}
+
+// Reason joins the reasons for logs and the cache.
+func (v Verdict) Reason() string {
+	return strings.Join(v.Reasons, ", ")
+}
+
+// Classifier scores repos against compiled rules. The zero value classifies
+// nothing as spam.
+type Classifier struct {
+	rules   Rules
+	signals []signal
+}
+
+type signal struct {
+	Signal
+	re *regexp.Regexp
+}
+
+// New compiles rules.
+func New(rules Rules) (Classifier, error) {
+	c := Classifier{rules: rules}
+	for _, s := range rules.Signals {
+		var alts []string
+		for _, kw := range s.Keywords {
+			alts = append(alts, `\b`+regexp.QuoteMeta(strings.ToLower(kw))+`\b`)
+		}
+		alts = append(alts, s.Patterns...)
+		if len(alts) == 0 {
+			continue
+		}
+		for _, f := range s.Fields {
+			if f != FieldName && f != FieldDescription && f != FieldTopics {
+				return c, fmt.Errorf("signal %q: unknown field %q", s.Reason, f)
+			}
+		}
+
+		re, err := regexp.Compile(`(?i)(` + strings.Join(alts, "|") + `)`)
+		if err != nil {
+			return c, fmt.Errorf("signal %q: %w", s.Reason, err)
+		}
+		c.signals = append(c.signals, signal{Signal: s, re: re})
+	}
+	return c, nil
+}
+
+// Load reads and compiles a rules file. An empty path yields DefaultRules.
+func Load(file string) (Classifier, error) {
+	if file == "" {
+		return New(DefaultRules())
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return Classifier{}, fmt.Errorf("read spam rules: %w", err)
+	}
+	var rules Rules
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return Classifier{}, fmt.Errorf("parse spam rules %s: %w", file, err)
+	}
+	c, err := New(rules)
+	if err != nil {
+		return c, fmt.Errorf("spam rules %s: %w", file, err)
+	}
+	return c, nil
+}
+
+// Enabled reports whether there is anything to classify with.
+func (c Classifier) Enabled() bool {
+	return c.rules.Threshold > 0 && (len(c.signals) > 0 || c.rules.NewOwner.Days > 0)
+}
+
+// Classify scores r at now.
+func (c Classifier) Classify(r Repo, now time.Time) Verdict {
+	var v Verdict
+	if !c.Enabled() {
+		return v
+	}
+
+	for _, s := range c.signals {
+		if s.matches(r) {
+			v.Score += s.Weight
+			v.Reasons = append(v.Reasons, s.Reason)
+		}
+	}
+
+	no := c.rules.NewOwner
+	if no.Days > 0 && !r.OwnerCreated.IsZero() && now.Sub(r.OwnerCreated) < time.Duration(no.Days)*24*time.Hour {
+		v.Score += no.Weight
+		v.Reasons = append(v.Reasons, fmt.Sprintf("owner younger than %d days", no.Days))
+	}
+
+	v.Spam = v.Score >= c.rules.Threshold
+	return v
+}
+
+// NeedsOwnerAge reports whether the owner's account age could still tip v
+// over the threshold, i.e. whether it's worth looking up.
+func (c Classifier) NeedsOwnerAge(v Verdict) bool {
+	no := c.rules.NewOwner
+	return !v.Spam && no.Days > 0 && no.Weight > 0 && v.Score+no.Weight >= c.rules.Threshold
+}
+
+func (s signal) matches(r Repo) bool {
+	fields := s.Fields
+	if len(fields) == 0 {
+		fields = []string{FieldName, FieldDescription, FieldTopics}
+	}
+	for _, f := range fields {
+		switch f {
+		case FieldName:
+			// names use dashes and underscores for spaces
+			if s.re.MatchString(strings.NewReplacer("-", " ", "_", " ").Replace(r.Name)) {
+				return true
+			}
+		case FieldDescription:
+			if s.re.MatchString(r.Description) {
+				return true
+			}
+		case FieldTopics:
+			for _, t := range r.Topics {
+				if s.re.MatchString(strings.ReplaceAll(t, "-", " ")) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// DefaultRules catch the usual suspects. A single strong signal is enough,
+// weak ones need company.
+func DefaultRules() Rules {
+	r := Rules{
+		Threshold: 1,
+		Signals: []Signal{
+			{
+				Reason:   "crypto drainer",
+				Keywords: []string{"drainer", "wallet drainer", "seed phrase", "private key stealer", "airdrop claim", "mev bot", "sniper bot"},
+				Weight:   1,
+			},
+			{
+				Reason:   "engagement spam",
+				Keywords: []string{"free followers", "followers bot", "follower bot", "view bot", "views bot", "like bot", "instagram followers", "tiktok followers", "tiktok views"},
+				Weight:   1,
+			},
+			{
+				Reason:   "cheats",
+				Keywords: []string{"free robux", "aimbot", "wallhack", "keygen", "cracked", "mod menu", "cheat engine"},
+				Weight:   1,
+			},
+			{
+				Reason:   "homework",
+				Keywords: []string{"homework", "assignment", "coursework", "lab exercises", "university project", "course project"},
+				Patterns: []string{`^(cs|ece|comp|csc|inf)\s?[0-9]{3,4}\b`},
+				Weight:   0.5,
+			},
+			{
+				Reason:   "crypto",
+				Keywords: []string{"crypto", "token", "web3", "airdrop", "solana", "pump fun"},
+				Fields:   []string{FieldName, FieldTopics},
+				Weight:   0.25,
+			},
+		},
+	}
+	r.NewOwner.Days = 30
+	r.NewOwner.Weight = 0.5
+	return r
+}
@@ -0,0 +1,102 @@
+package spam_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/spam"
+)
+
+func TestClassifier_DefaultRules(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	newOwner := now.Add(-5 * 24 * time.Hour)
+	oldOwner := now.Add(-5 * 365 * 24 * time.Hour)
+
+	cl, err := spam.Load("")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		repo    spam.Repo
+		spam    bool
+		reasons []string
+	}{
+		{"clean", spam.Repo{Name: "golangoss-bluesky", Description: "A bot posting Go projects to Bluesky"}, false, nil},
+		{"drainer in description", spam.Repo{Name: "eth-tools", Description: "Wallet DRAINER with auto withdraw"}, true, []string{"crypto drainer"}},
+		{"drainer in name", spam.Repo{Name: "seed_phrase-grabber"}, true, []string{"crypto drainer"}},
+		{"follower bot topic", spam.Repo{Name: "insta", Topics: []string{"free-followers"}}, true, []string{"engagement spam"}},
+		{"cheats", spam.Repo{Name: "roblox", Description: "free robux generator"}, true, []string{"cheats"}},
+		{"homework alone is not enough", spam.Repo{Name: "go-homework", Description: "my solutions"}, false, []string{"homework"}},
+		{"homework course code", spam.Repo{Name: "cs101-assignment-3"}, false, []string{"homework"}},
+		{"homework by a new account", spam.Repo{Name: "go-homework", OwnerCreated: newOwner}, true, []string{"homework", "owner younger than 30 days"}},
+		{"homework by an old account", spam.Repo{Name: "go-homework", OwnerCreated: oldOwner}, false, []string{"homework"}},
+		{"keywords match whole words", spam.Repo{Name: "tokenizer", Description: "a cryptographic assignments-free lexer"}, false, nil},
+		{"crypto topic is weak", spam.Repo{Name: "solana-go", Topics: []string{"solana", "web3"}}, false, []string{"crypto"}},
+		{"weak signals add up", spam.Repo{Name: "token-airdrop", Description: "homework", OwnerCreated: newOwner}, true, []string{"homework", "crypto", "owner younger than 30 days"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := cl.Classify(tc.repo, now)
+			assert.Equal(t, tc.spam, v.Spam, "score %.2f", v.Score)
+			assert.Equal(t, tc.reasons, v.Reasons)
+		})
+	}
+}
+
+func TestClassifier_NeedsOwnerAge(t *testing.T) {
+	cl, err := spam.New(spam.DefaultRules())
+	require.NoError(t, err)
+	now := time.Now()
+
+	testCases := []struct {
+		name string
+		repo spam.Repo
+		want bool
+	}{
+		{"clean", spam.Repo{Name: "gizmo"}, false},
+		{"borderline", spam.Repo{Name: "go-homework"}, true},
+		{"already spam", spam.Repo{Name: "wallet-drainer"}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, cl.NeedsOwnerAge(cl.Classify(tc.repo, now)))
+		})
+	}
+}
+
+func TestClassifier_ZeroValue(t *testing.T) {
+	var cl spam.Classifier
+	assert.False(t, cl.Enabled())
+	assert.False(t, cl.Classify(spam.Repo{Name: "wallet-drainer"}, time.Now()).Spam)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "spam.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{
+		"threshold": 0.5,
+		"signals": [{"reason": "leetcode", "patterns": ["^leetcode"], "fields": ["name"], "weight": 0.5}]
+	}`), 0o600))
+
+	cl, err := spam.Load(file)
+	require.NoError(t, err)
+	assert.True(t, cl.Classify(spam.Repo{Name: "leetcode-go"}, time.Now()).Spam)
+	assert.False(t, cl.Classify(spam.Repo{Name: "go", Description: "leetcode solutions"}, time.Now()).Spam)
+
+	for name, content := range map[string]string{
+		"bad-pattern.json": `{"threshold": 1, "signals": [{"reason": "x", "patterns": ["("]}]}`,
+		"bad-field.json":   `{"threshold": 1, "signals": [{"reason": "x", "keywords": ["y"], "fields": ["readme"]}]}`,
+		"bad-json.json":    `{`,
+	} {
+		file := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+		_, err := spam.Load(file)
+		assert.Error(t, err, name)
+	}
+}
@@ -1,20 +1,27 @@
 // Package stats serves bot health metrics over HTTP: uptime, memory,
-// GC counters and a summary of the S3 cache bucket.
+// GC counters, the remaining GitHub API quota and a summary of the S3
+// cache bucket.
 package stats
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"errors"
 	"html/template"
 	"log/slog"
+	"mime"
 	"net/http"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/minio/minio-go/v7"
+	"github.com/till/golangoss-bluesky/internal/moderation"
+	"github.com/till/golangoss-bluesky/internal/submission"
 )
 
 const (
@@ -26,6 +33,9 @@ const (
 
 	// recentLimit caps how many recent cache entries we display.
 	recentLimit = 50
+
+	// maxSubmissionBody caps the size of a submission request.
+	maxSubmissionBody = 4 << 10
 )
 
 // S3Stats summarizes the state of the cache bucket.
@@ -75,11 +85,42 @@ func MinioProvider(mc *minio.Client, bucket string) S3Provider {
 	}
 }
 
+// RateLimit is the remaining API quota of one rate limit category.
+type RateLimit struct {
+	Category  string
+	Limit     int
+	Remaining int
+	Reset     time.Time
+}
+
+// RateLimitProvider returns the last known API quotas.
+type RateLimitProvider func() []RateLimit
+
+// SubmissionQueue takes repos suggested for posting.
+type SubmissionQueue interface {
+	Add(ctx context.Context, repoURL string) (submission.Submission, error)
+}
+
+// ModerationQueue holds the posts awaiting a moderator's decision.
+type ModerationQueue interface {
+	Pending(ctx context.Context) ([]moderation.Item, error)
+	Approve(ctx context.Context, id, text string) error
+	Skip(ctx context.Context, id string) error
+	Block(ctx context.Context, id string) error
+}
+
 // Server exposes bot health metrics over HTTP.
 type Server struct {
 	addr      string
 	startTime time.Time
 	s3        S3Provider
+	rates     RateLimitProvider
+
+	submitToken string
+	submissions SubmissionQueue
+
+	adminToken string
+	moderation ModerationQueue
 
 	mu       sync.Mutex
 	cachedAt time.Time
@@ -96,11 +137,41 @@ func NewServer(addr string, s3 S3Provider) *Server {
 	}
 }
 
+// WithRateLimits adds the API quota section to the page.
+func (s *Server) WithRateLimits(p RateLimitProvider) *Server {
+	s.rates = p
+	return s
+}
+
+// WithSubmissions adds the POST /submissions endpoint, which queues a
+// GitHub repo for posting. Requests must carry token as a bearer token; an
+// empty token leaves the endpoint off.
+func (s *Server) WithSubmissions(token string, q SubmissionQueue) *Server {
+	s.submitToken = token
+	s.submissions = q
+	return s
+}
+
+// WithModeration adds the /admin page listing the posts awaiting approval.
+// It's protected by HTTP basic auth with token as the password; an empty
+// token leaves the page off.
+func (s *Server) WithModeration(token string, q ModerationQueue) *Server {
+	s.adminToken = token
+	s.moderation = q
+	return s
+}
+
 // ListenAndServe blocks until ctx is cancelled or the server errors.
 // A ctx cancel triggers a graceful shutdown and returns nil.
 func (s *Server) ListenAndServe(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.HandleStats)
+	if s.submitToken != "" && s.submissions != nil {
+		mux.HandleFunc("/submissions", s.HandleSubmission)
+	}
+	if s.adminToken != "" && s.moderation != nil {
+		mux.HandleFunc("/admin", s.HandleAdmin)
+	}
 
 	srv := &http.Server{
 		Addr:              s.addr,
@@ -154,6 +225,7 @@ func (s *Server) HandleStats(w http.ResponseWriter, r *http.Request) {
 		CacheCount:  cache.Objects,
 		CacheSize:   humanize.IBytes(uint64(cache.TotalSize)),
 		Recent:      toViewEntries(cache.Recent),
+		RateLimits:  s.rateLimits(),
 		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
 	}
 	if cache.Err != nil {
@@ -166,6 +238,149 @@ func (s *Server) HandleStats(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleSubmission queues the repo in the request, given as {"url": "..."}
+// or as a "url" form value.
+func (s *Server) HandleSubmission(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSubmissionBody)
+	var req struct {
+		URL string `json:"url"`
+	}
+	if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct == "application/json" {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+	} else {
+		req.URL = r.FormValue("url")
+	}
+
+	sub, err := s.submissions.Add(r.Context(), req.URL)
+	if errors.Is(err, submission.ErrInvalidURL) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "queue submission", "url", req.URL, "error", err)
+		http.Error(w, "could not queue the submission", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "repo submitted", "repo", sub.FullName())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]string{"repo": sub.FullName()})
+}
+
+// authorized checks the request's bearer token.
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.submitToken != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(s.submitToken)) == 1
+}
+
+// HandleAdmin lists the pending posts on GET and applies a moderator's
+// decision on POST: the form's "action" (approve, skip or block) on item
+// "id", with the edited "text" when approving.
+func (s *Server) HandleAdmin(w http.ResponseWriter, r *http.Request) {
+	if _, password, ok := r.BasicAuth(); !ok || s.adminToken == "" ||
+		subtle.ConstantTimeCompare([]byte(password), []byte(s.adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="golangoss-bluesky admin"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.renderAdmin(w, r)
+	case http.MethodPost:
+		s.moderate(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) renderAdmin(w http.ResponseWriter, r *http.Request) {
+	items, err := s.moderation.Pending(r.Context())
+	data := adminData{GeneratedAt: time.Now().UTC().Format(time.RFC3339)}
+	if err != nil {
+		data.Error = err.Error()
+	}
+	now := time.Now()
+	for _, it := range items {
+		data.Items = append(data.Items, adminItem{
+			ID:       it.ID,
+			Campaign: it.Campaign,
+			Repo:     it.Repo,
+			URL:      it.URL,
+			Text:     it.Post.Text,
+			Queued:   humanize.RelTime(it.CreatedAt, now, "ago", "from now"),
+			Expires:  humanize.RelTime(it.ExpiresAt, now, "ago", "from now"),
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := adminTmpl.Execute(w, data); err != nil {
+		slog.ErrorContext(r.Context(), "render admin", "error", err)
+	}
+}
+
+func (s *Server) moderate(w http.ResponseWriter, r *http.Request) {
+	// basic auth credentials are sent along with cross-site form posts
+	if origin := r.Header.Get("Origin"); origin != "" && !sameHost(origin, r.Host) {
+		http.Error(w, "cross-origin request", http.StatusForbidden)
+		return
+	}
+
+	ctx := r.Context()
+	id, action := r.FormValue("id"), r.FormValue("action")
+	var err error
+	switch action {
+	case "approve":
+		err = s.moderation.Approve(ctx, id, r.FormValue("text"))
+	case "skip":
+		err = s.moderation.Skip(ctx, id)
+	case "block":
+		err = s.moderation.Block(ctx, id)
+	default:
+		http.Error(w, "unknown action", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case errors.Is(err, moderation.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, moderation.ErrInvalidText):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		slog.ErrorContext(ctx, "moderate", "id", id, "action", action, "error", err)
+		http.Error(w, "could not save the decision", http.StatusInternalServerError)
+		return
+	}
+
+	slog.InfoContext(ctx, "moderated", "id", id, "action", action)
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// sameHost reports whether the origin URL points at host.
+func sameHost(origin, host string) bool {
+	_, rest, ok := strings.Cut(origin, "://")
+	return ok && strings.EqualFold(rest, host)
+}
+
 func (s *Server) fetchS3(ctx context.Context) S3Stats {
 	s.mu.Lock()
 	if !s.cachedAt.IsZero() && time.Since(s.cachedAt) < s3CacheTTL {
@@ -185,6 +400,26 @@ func (s *Server) fetchS3(ctx context.Context) S3Stats {
 	return out
 }
 
+func (s *Server) rateLimits() []rateLimitEntry {
+	if s.rates == nil {
+		return nil
+	}
+	limits := s.rates()
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Category < limits[j].Category })
+
+	out := make([]rateLimitEntry, 0, len(limits))
+	now := time.Now()
+	for _, l := range limits {
+		out = append(out, rateLimitEntry{
+			Category:  l.Category,
+			Remaining: l.Remaining,
+			Limit:     l.Limit,
+			Reset:     humanize.RelTime(l.Reset, now, "ago", "from now"),
+		})
+	}
+	return out
+}
+
 func lastGC(nanos uint64) string {
 	if nanos == 0 {
 		return "never"
@@ -205,9 +440,17 @@ type viewData struct {
 	CacheSize   string
 	CacheError  string
 	Recent      []recentEntry
+	RateLimits  []rateLimitEntry
 	GeneratedAt string
 }
 
+type rateLimitEntry struct {
+	Category  string
+	Remaining int
+	Limit     int
+	Reset     string
+}
+
 type recentEntry struct {
 	Key  string
 	Size string
@@ -227,8 +470,26 @@ func toViewEntries(entries []CacheEntry) []recentEntry {
 	return out
 }
 
+type adminData struct {
+	Items       []adminItem
+	Error       string
+	GeneratedAt string
+}
+
+type adminItem struct {
+	ID       string
+	Campaign string
+	Repo     string
+	URL      string
+	Text     string
+	Queued   string
+	Expires  string
+}
+
 var statsTmpl = template.Must(template.New("stats").Parse(pageHTML))
 
+var adminTmpl = template.Must(template.New("admin").Parse(adminHTML))
+
 const pageHTML = `<!doctype html>
 <html lang="en">
 <head>
@@ -270,6 +531,18 @@ const pageHTML = `<!doctype html>
     <dt>Pause total</dt><dd>{{.PauseTotal}}</dd>
   </dl>
 
+  {{- if .RateLimits}}
+  <h2>GitHub API</h2>
+  <table>
+    <thead><tr><th>Category</th><th class="num">Remaining</th><th>Resets</th></tr></thead>
+    <tbody>
+    {{- range .RateLimits}}
+      <tr><td>{{.Category}}</td><td class="num">{{.Remaining}} / {{.Limit}}</td><td>{{.Reset}}</td></tr>
+    {{- end}}
+    </tbody>
+  </table>
+  {{- end}}
+
   <h2>Cache (S3)</h2>
   {{- if .CacheError}}
   <p class="error">Error: {{.CacheError}}</p>
@@ -294,3 +567,47 @@ const pageHTML = `<!doctype html>
 </body>
 </html>
 `
+
+const adminHTML = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>golangoss-bluesky moderation</title>
+  <style>
+    body { font-family: system-ui, sans-serif; max-width: 720px; margin: 2rem auto; padding: 0 1rem; color: #222; }
+    h1 { font-size: 1.4rem; margin-bottom: .25rem; }
+    h2 { font-size: 1.05rem; margin: 0 0 .25rem; }
+    .item { border: 1px solid #eee; border-radius: 4px; padding: .75rem 1rem; margin-top: 1rem; }
+    .meta { color: #666; font-size: .85rem; margin-bottom: .5rem; }
+    textarea { width: 100%; box-sizing: border-box; font: inherit; }
+    .actions { display: flex; gap: .5rem; margin-top: .5rem; }
+    .error { color: #b00020; }
+    footer { margin-top: 2rem; color: #666; font-size: .85rem; }
+  </style>
+</head>
+<body>
+  <h1>golangoss-bluesky moderation</h1>
+  {{- if .Error}}
+  <p class="error">Error: {{.Error}}</p>
+  {{- end}}
+
+  {{- range .Items}}
+  <form class="item" method="post" action="/admin">
+    <h2><a href="{{.URL}}">{{.Repo}}</a></h2>
+    <div class="meta">{{if .Campaign}}{{.Campaign}} · {{end}}queued {{.Queued}} · expires {{.Expires}}</div>
+    <input type="hidden" name="id" value="{{.ID}}">
+    <textarea name="text" rows="8">{{.Text}}</textarea>
+    <div class="actions">
+      <button name="action" value="approve">Approve</button>
+      <button name="action" value="skip">Skip</button>
+      <button name="action" value="block">Blocklist</button>
+    </div>
+  </form>
+  {{- else}}
+  <p>Nothing awaiting approval.</p>
+  {{- end}}
+
+  <footer>Generated {{.GeneratedAt}}</footer>
+</body>
+</html>
+`
@@ -4,11 +4,15 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/bluesky-social/indigo/api/bsky"
 	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/moderation"
 	"github.com/till/golangoss-bluesky/internal/stats"
+	"github.com/till/golangoss-bluesky/internal/submission"
 )
 
 func TestHandleStats_RendersHTMLWithCacheSummary(t *testing.T) {
@@ -54,3 +58,146 @@ func TestHandleStats_NotFoundForUnknownPath(t *testing.T) {
 	srv.HandleStats(w, req)
 	require.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestHandleStats_RendersRateLimits(t *testing.T) {
+	srv := stats.NewServer(":0", nil).WithRateLimits(func() []stats.RateLimit {
+		return []stats.RateLimit{
+			{Category: "search", Limit: 30, Remaining: 12, Reset: time.Now().Add(time.Minute)},
+			{Category: "core", Limit: 5000, Remaining: 4321, Reset: time.Now().Add(time.Hour)},
+		}
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	srv.HandleStats(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	require.Contains(t, body, "GitHub API")
+	require.Contains(t, body, "12 / 30")
+	require.Contains(t, body, "4321 / 5000")
+}
+
+type fakeQueue []string
+
+func (q *fakeQueue) Add(_ context.Context, repoURL string) (submission.Submission, error) {
+	owner, name, err := submission.ParseURL(repoURL)
+	if err != nil {
+		return submission.Submission{}, err
+	}
+	*q = append(*q, owner+"/"+name)
+	return submission.Submission{Owner: owner, Name: name}, nil
+}
+
+func TestHandleSubmission(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		auth        string
+		contentType string
+		body        string
+		wantCode    int
+		wantQueued  []string
+	}{
+		{name: "json", method: http.MethodPost, auth: "Bearer s3cret", contentType: "application/json",
+			body: `{"url": "https://github.com/owner/repo"}`, wantCode: http.StatusAccepted, wantQueued: []string{"owner/repo"}},
+		{name: "form", method: http.MethodPost, auth: "Bearer s3cret", contentType: "application/x-www-form-urlencoded",
+			body: "url=https%3A%2F%2Fgithub.com%2Fowner%2Frepo", wantCode: http.StatusAccepted, wantQueued: []string{"owner/repo"}},
+		{name: "not github", method: http.MethodPost, auth: "Bearer s3cret", contentType: "application/json",
+			body: `{"url": "https://example.com/owner/repo"}`, wantCode: http.StatusBadRequest},
+		{name: "invalid json", method: http.MethodPost, auth: "Bearer s3cret", contentType: "application/json",
+			body: `{"url":`, wantCode: http.StatusBadRequest},
+		{name: "wrong token", method: http.MethodPost, auth: "Bearer nope", contentType: "application/json",
+			body: `{"url": "https://github.com/owner/repo"}`, wantCode: http.StatusUnauthorized},
+		{name: "no token", method: http.MethodPost, contentType: "application/json",
+			body: `{"url": "https://github.com/owner/repo"}`, wantCode: http.StatusUnauthorized},
+		{name: "get", method: http.MethodGet, auth: "Bearer s3cret", wantCode: http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var q fakeQueue
+			srv := stats.NewServer(":0", nil).WithSubmissions("s3cret", &q)
+
+			req := httptest.NewRequest(tc.method, "/submissions", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			if tc.auth != "" {
+				req.Header.Set("Authorization", tc.auth)
+			}
+			w := httptest.NewRecorder()
+			srv.HandleSubmission(w, req)
+
+			require.Equal(t, tc.wantCode, w.Code, w.Body.String())
+			require.Equal(t, tc.wantQueued, []string(q))
+			if tc.wantCode == http.StatusAccepted {
+				require.JSONEq(t, `{"repo": "owner/repo"}`, w.Body.String())
+			}
+		})
+	}
+}
+
+type fakeModeration struct {
+	items     []moderation.Item
+	decisions []string
+}
+
+func (m *fakeModeration) Pending(context.Context) ([]moderation.Item, error) {
+	return m.items, nil
+}
+
+func (m *fakeModeration) Approve(_ context.Context, id, text string) error {
+	return m.decide("approve " + id + " " + text)
+}
+
+func (m *fakeModeration) Skip(_ context.Context, id string) error {
+	return m.decide("skip " + id)
+}
+
+func (m *fakeModeration) Block(_ context.Context, id string) error {
+	return m.decide("block " + id)
+}
+
+func (m *fakeModeration) decide(d string) error {
+	if !strings.Contains(d, " 1") {
+		return moderation.ErrNotFound
+	}
+	m.decisions = append(m.decisions, d)
+	return nil
+}
+
+func TestHandleAdmin(t *testing.T) {
+	q := &fakeModeration{items: []moderation.Item{{
+		ID:   "1",
+		Repo: "owner/repo",
+		URL:  "https://github.com/owner/repo",
+		Post: &bsky.FeedPost{Text: "repo by @owner (⭐️ 42)"},
+	}}}
+	srv := stats.NewServer(":0", nil).WithModeration("s3cret", q)
+
+	do := func(method, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("admin", "s3cret")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		srv.HandleAdmin(w, req)
+		return w
+	}
+
+	w := do(http.MethodGet, "", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "owner/repo")
+	require.Contains(t, w.Body.String(), "repo by @owner (⭐️ 42)")
+
+	w = do(http.MethodPost, "id=1&action=approve&text=edited", map[string]string{"Origin": "http://example.com"})
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	w = do(http.MethodPost, "id=1&action=block", nil)
+	require.Equal(t, http.StatusSeeOther, w.Code)
+	require.Equal(t, []string{"approve 1 edited", "block 1"}, q.decisions)
+
+	require.Equal(t, http.StatusNotFound, do(http.MethodPost, "id=2&action=skip", nil).Code)
+	require.Equal(t, http.StatusBadRequest, do(http.MethodPost, "id=1&action=post", nil).Code)
+	require.Equal(t, http.StatusForbidden, do(http.MethodPost, "id=1&action=skip", map[string]string{"Origin": "https://evil.example"}).Code)
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "", map[string]string{"Authorization": "Basic Zm9vOmJhcg=="}).Code)
+}
@@ -0,0 +1,141 @@
+// Package submission queues repos the team suggests for posting. Entries
+// live in the cache bucket as "submission:<time>:<owner>/<name>", so
+// listing the prefix returns them oldest first.
+package submission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const prefix = "submission:"
+
+// ttl is how long a submission waits in the queue. Anything not drained by
+// then is dropped by the cleanup routine.
+const ttl = 30 * 24 * time.Hour
+
+// timeLayout sorts lexicographically in time order.
+const timeLayout = "20060102T150405.000000000Z"
+
+var (
+	githubOwner = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+	githubName  = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+
+	// ErrInvalidURL is returned for anything but a GitHub repo URL.
+	ErrInvalidURL = errors.New("not a GitHub repo URL")
+)
+
+// Cache is the subset of cache.ClientS3 the queue uses.
+type Cache interface {
+	Set(ctx context.Context, key string, value any, exp time.Duration) error
+	Del(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Submission is a queued repo.
+type Submission struct {
+	Key         string // cache key
+	Owner       string
+	Name        string
+	SubmittedAt time.Time
+}
+
+// FullName returns "owner/name".
+func (s Submission) FullName() string {
+	return s.Owner + "/" + s.Name
+}
+
+// Queue is the submission queue.
+type Queue struct {
+	cache Cache
+}
+
+// NewQueue returns a queue backed by c.
+func NewQueue(c Cache) Queue {
+	return Queue{cache: c}
+}
+
+// Add queues the repo at repoURL, e.g. "https://github.com/owner/name".
+// The error wraps ErrInvalidURL when repoURL isn't a GitHub repo.
+func (q Queue) Add(ctx context.Context, repoURL string) (Submission, error) {
+	owner, name, err := ParseURL(repoURL)
+	if err != nil {
+		return Submission{}, err
+	}
+
+	s := Submission{Owner: owner, Name: name, SubmittedAt: time.Now().UTC()}
+	s.Key = prefix + s.SubmittedAt.Format(timeLayout) + ":" + s.FullName()
+	if err := q.cache.Set(ctx, s.Key, repoURL, ttl); err != nil {
+		return Submission{}, fmt.Errorf("queue %s: %w", s.FullName(), err)
+	}
+	return s, nil
+}
+
+// List returns the queued submissions, oldest first.
+func (q Queue) List(ctx context.Context) ([]Submission, error) {
+	keys, err := q.cache.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(keys)
+
+	out := make([]Submission, 0, len(keys))
+	for _, k := range keys {
+		s, ok := parseKey(k)
+		if !ok {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Remove takes s off the queue.
+func (q Queue) Remove(ctx context.Context, s Submission) error {
+	return q.cache.Del(ctx, s.Key)
+}
+
+// ParseURL returns owner and name of a GitHub repo URL. The scheme, a
+// trailing ".git" and paths into the repo are optional.
+func ParseURL(repoURL string) (owner, name string, err error) {
+	raw := strings.TrimSpace(repoURL)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(strings.TrimPrefix(u.Hostname(), "www."), "github.com") {
+		return "", "", fmt.Errorf("%q: %w", repoURL, ErrInvalidURL)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("%q: %w", repoURL, ErrInvalidURL)
+	}
+	owner, name = parts[0], strings.TrimSuffix(parts[1], ".git")
+	if !githubOwner.MatchString(owner) || !githubName.MatchString(name) || name == "." || name == ".." {
+		return "", "", fmt.Errorf("%q: %w", repoURL, ErrInvalidURL)
+	}
+	return owner, name, nil
+}
+
+func parseKey(key string) (Submission, bool) {
+	at, fullName, ok := strings.Cut(strings.TrimPrefix(key, prefix), ":")
+	if !ok {
+		return Submission{}, false
+	}
+	t, err := time.Parse(timeLayout, at)
+	if err != nil {
+		return Submission{}, false
+	}
+	owner, name, ok := strings.Cut(fullName, "/")
+	if !ok {
+		return Submission{}, false
+	}
+	return Submission{Key: key, Owner: owner, Name: name, SubmittedAt: t}, true
+}
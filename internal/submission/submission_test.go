@@ -0,0 +1,67 @@
+package submission_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/till/golangoss-bluesky/internal/submission"
+	"github.com/till/golangoss-bluesky/internal/testutil"
+)
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	cache := testutil.MemCache{"repo:1": true}
+	q := submission.NewQueue(cache)
+
+	first, err := q.Add(ctx, "https://github.com/owner/first")
+	require.NoError(t, err)
+	_, err = q.Add(ctx, "github.com/Owner/second.git")
+	require.NoError(t, err)
+
+	subs, err := q.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 2)
+	assert.Equal(t, "owner/first", subs[0].FullName())
+	assert.Equal(t, "Owner/second", subs[1].FullName())
+	assert.Equal(t, first, subs[0])
+
+	require.NoError(t, q.Remove(ctx, subs[0]))
+	subs, err = q.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "Owner/second", subs[0].FullName())
+	assert.Contains(t, cache, "repo:1")
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		owner string
+		name  string
+	}{
+		{url: "https://github.com/till/golangoss-bluesky", owner: "till", name: "golangoss-bluesky"},
+		{url: "https://www.github.com/till/golangoss-bluesky/", owner: "till", name: "golangoss-bluesky"},
+		{url: "https://github.com/till/golangoss-bluesky/tree/main/internal", owner: "till", name: "golangoss-bluesky"},
+		{url: "github.com/golang/go.git", owner: "golang", name: "go"},
+		{url: "https://gitlab.com/till/golangoss-bluesky"},
+		{url: "https://github.com/till"},
+		{url: "https://github.com/-till/repo"},
+		{url: "https://github.com/till/.."},
+		{url: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			owner, name, err := submission.ParseURL(tc.url)
+			if tc.owner == "" {
+				assert.ErrorIs(t, err, submission.ErrInvalidURL)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.owner, owner)
+			assert.Equal(t, tc.name, name)
+		})
+	}
+}
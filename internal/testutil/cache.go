@@ -0,0 +1,47 @@
+// Package testutil holds fakes shared by the tests of several packages.
+package testutil
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// MemCache is an in-memory stand-in for the S3 cache. Values are stored as
+// given, not JSON encoded; Get returns strings as they are and "true" for
+// anything else, like the flags the bot caches.
+type MemCache map[string]any
+
+func (m MemCache) Get(_ context.Context, key string) (string, error) {
+	v, ok := m[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return "true", nil
+}
+
+func (m MemCache) Set(_ context.Context, key string, value any, _ time.Duration) error {
+	m[key] = value
+	return nil
+}
+
+func (m MemCache) Del(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+// List returns the keys starting with prefix, in no particular order.
+func (m MemCache) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for k := range m {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}